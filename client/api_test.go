@@ -0,0 +1,129 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/sangjinsu/websocket-multiplayer/client"
+)
+
+// 모듈 밖의 코드처럼 client가 내보낸 이름만 사용한다
+
+// scriptedServer answers login with a welcome and a full game_state, and
+// refuses chat. It reports the type of every message the client sends.
+func scriptedServer(t *testing.T) (url string, sent <-chan client.MessageType) {
+	t.Helper()
+	types := make(chan client.MessageType, 16)
+	upgrader := websocket.Upgrader{Subprotocols: []string{"json"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var in struct {
+				Type client.MessageType `json:"type"`
+				ID   string             `json:"id"`
+			}
+			if err := conn.ReadJSON(&in); err != nil {
+				return
+			}
+			types <- in.Type
+			var out []any
+			switch in.Type {
+			case client.MessageTypeLogin:
+				out = []any{
+					map[string]any{"type": client.MessageTypeWelcome, "payload": client.WelcomePayload{
+						ID: "p1", Name: "ada", Room: "lobby", ResumeToken: "tok", World: client.WorldConfig{Width: 800, Height: 600},
+					}},
+					map[string]any{"type": client.MessageTypeGameState, "payload": client.GameStatePayload{
+						Seq: 1, Tick: 10, ServerTime: 1700000000000, TickInterval: 50, Full: true,
+						Players: map[string]client.PlayerState{"p1": {ID: "p1", Name: "ada", X: 100, Y: 200}},
+					}},
+				}
+			case client.MessageTypeChat:
+				out = []any{map[string]any{"type": client.MessageTypeError, "payload": client.ErrorPayload{
+					Code: client.ErrorCodeRateLimited, Message: "slow down", Type: in.Type, ID: in.ID,
+				}}}
+			}
+			for _, msg := range out {
+				if err := conn.WriteJSON(msg); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), types
+}
+
+// next waits for the next event of type T
+func next[T client.Event](t *testing.T, c *client.Client) T {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case ev := <-c.Events():
+			if ev, ok := ev.(T); ok {
+				return ev
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("no %T event", zero)
+			return zero
+		}
+	}
+}
+
+func TestExportedAPI(t *testing.T) {
+	url, sent := scriptedServer(t)
+	c, err := client.Connect(context.Background(), url, client.Options{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var welcome client.WelcomePayload
+	if welcome, err = c.Login("ada", ""); err != nil {
+		t.Fatal(err)
+	}
+	if welcome.ID != "p1" || welcome.ResumeToken != "tok" {
+		t.Errorf("welcome %+v, want p1 with a resume token", welcome)
+	}
+
+	st := next[client.GameState](t, c)
+	var self client.PlayerState = st.State[welcome.ID]
+	if self.X != 100 || self.Y != 200 {
+		t.Errorf("state of p1 %+v, want at (100, 200)", self)
+	}
+	var snap client.Snapshot = st.Snapshot()
+	buf := client.NewBuffer(4)
+	buf.Add(snap)
+	if got := buf.At(snap.ServerTime)[welcome.ID]; got != (client.Position{X: 100, Y: 200}) {
+		t.Errorf("interpolated position %+v, want (100, 200)", got)
+	}
+	var cfg client.WorldConfig = c.World().Config()
+	if cfg.Width != 800 {
+		t.Errorf("world width %g, want 800", cfg.Width)
+	}
+
+	if err := c.Send(client.MessageTypeChat, client.ChatRequest{Channel: client.ChatRoom, Text: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if e := next[client.Error](t, c); e.Code != client.ErrorCodeRateLimited {
+		t.Errorf("error %+v, want rate_limited", e)
+	}
+
+	want := []client.MessageType{client.MessageTypeLogin, client.MessageTypeStateAck, client.MessageTypeChat}
+	for _, w := range want {
+		if got := <-sent; got != w {
+			t.Errorf("server got %s, want %s", got, w)
+		}
+	}
+}
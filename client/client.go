@@ -0,0 +1,432 @@
+// Package client is a headless Go client for the /ws protocol, for
+// integration tests, load tools and bots written in Go. It speaks the same
+// wire types the server sends (re-exported in types.go), keeps a mirror of
+// the world in sync and acknowledges snapshots so the server can send deltas.
+//
+//	c, err := client.Connect(ctx, "ws://localhost:3000/ws", client.Options{Room: "abc"})
+//	if err != nil { ... }
+//	defer c.Close()
+//	welcome, err := c.Login("bot", "#FF6B6B")
+//	c.SendKey("d")
+//	for ev := range c.Events() {
+//		switch ev := ev.(type) {
+//		case client.GameState:
+//			fmt.Println(ev.Tick, ev.State[welcome.ID].X)
+//		}
+//	}
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 옵션 기본값
+const (
+	DefaultEventBuffer = 256
+	DefaultTimeout     = 5 * time.Second
+)
+
+// ErrNoWelcome is returned by Login when the server doesn't answer in time.
+// A login the server refuses (invalid, room full, draining, replay room)
+// fails with its Error instead.
+var ErrNoWelcome = errors.New("no welcome from the server")
+
+// ErrClosed is returned when sending on a connection that has ended
+var ErrClosed = errors.New("connection closed")
+
+// Options configures a connection. The zero value joins the default room
+// with JSON.
+type Options struct {
+	Room        string        // ?room=, 비우면 서버 기본 방
+	Encoding    string        // "json"(기본) 또는 "msgpack", 서브프로토콜로 협상
+	EventBuffer int           // Events 채널 크기, 가득 차면 새 이벤트를 버림 (World는 그대로 갱신)
+	Timeout     time.Duration // 연결(handshake)과 Login이 응답을 기다리는 시간
+	Header      http.Header   // handshake에 더할 헤더
+}
+
+// Stats counts a connection's traffic
+type Stats struct {
+	MessagesIn    uint64
+	MessagesOut   uint64
+	BytesIn       uint64
+	BytesOut      uint64
+	DroppedEvents uint64 // Events가 가득 차서 버린 이벤트
+	BadMessages   uint64 // 디코딩하지 못해 버린 메시지
+}
+
+// Client is one connection to the server
+type Client struct {
+	conn    *websocket.Conn
+	codec   codec
+	timeout time.Duration
+	world   *World
+
+	writeMu sync.Mutex // websocket.Conn은 동시 쓰기 불가
+	seq     atomic.Uint32
+	loginN  atomic.Uint64 // login 메시지 id 번호
+
+	events  chan Event
+	welcome chan models.WelcomePayload // Login이 기다리는 welcome
+	refused chan Error                 // Login이 기다리는, login을 거절한 error
+	loginMu sync.Mutex
+	loginID string // 응답을 기다리는 login의 id (loginMu로 보호)
+	done    chan struct{}
+	err     error // done이 닫힌 뒤에만 읽음
+
+	messagesIn, messagesOut atomic.Uint64
+	bytesIn, bytesOut       atomic.Uint64
+	dropped, badMessages    atomic.Uint64
+}
+
+// Connect opens a connection to the server's websocket endpoint, e.g.
+// "ws://localhost:3000/ws". The connection doesn't join the world until
+// Login.
+func Connect(ctx context.Context, rawURL string, opts Options) (*Client, error) {
+	codec, err := newCodec(opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	if opts.EventBuffer <= 0 {
+		opts.EventBuffer = DefaultEventBuffer
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Room != "" {
+		q := u.Query()
+		q.Set("room", opts.Room)
+		u.RawQuery = q.Encode()
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: opts.Timeout,
+		Subprotocols:     []string{codec.name()},
+	}
+	conn, resp, err := dialer.DialContext(ctx, u.String(), opts.Header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("connecting to %s: %w (HTTP %d)", u, err, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("connecting to %s: %w", u, err)
+	}
+
+	c := &Client{
+		conn:    conn,
+		codec:   codec,
+		timeout: opts.Timeout,
+		world:   newWorld(),
+		events:  make(chan Event, opts.EventBuffer),
+		welcome: make(chan models.WelcomePayload, 1),
+		refused: make(chan Error, 1),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// Login joins the world as name with color ("" lets the server pick) and
+// waits for the welcome. The welcome also comes through Events. The login
+// carries its own id, so a refusal that arrives late for an earlier attempt
+// is not taken as the answer to this one.
+func (c *Client) Login(name, color string) (WelcomePayload, error) {
+	id := fmt.Sprintf("login-%d", c.loginN.Add(1))
+	c.loginMu.Lock()
+	c.loginID = id
+	c.loginMu.Unlock()
+	// 이전 시도의 거절이 남아 있으면 버림
+	select {
+	case <-c.refused:
+	default:
+	}
+
+	msg := models.Message{Type: models.MessageTypeLogin, ID: id, Payload: models.PlayerLogin{Name: name, Color: color}}
+	if err := c.send(msg); err != nil {
+		return models.WelcomePayload{}, err
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case w := <-c.welcome:
+			return w, nil
+		case e := <-c.refused:
+			if e.ID != id {
+				continue // 이전 시도에 대한 거절
+			}
+			return models.WelcomePayload{}, e
+		case <-c.done:
+			return models.WelcomePayload{}, c.err
+		case <-timer.C:
+			return models.WelcomePayload{}, ErrNoWelcome
+		}
+	}
+}
+
+// SendKey sends a WASD key press and returns its input sequence number; the
+// server has applied it once the player's LastInputSeq reaches it
+func (c *Client) SendKey(key string) (uint32, error) {
+	seq := c.seq.Add(1)
+	return seq, c.Send(models.MessageTypeInput, models.InputRequest{Seq: seq, Key: key})
+}
+
+// SendVelocity sends a touch/click velocity (px/s) and returns its input
+// sequence number, like SendKey
+func (c *Client) SendVelocity(vx, vy float64) (uint32, error) {
+	seq := c.seq.Add(1)
+	return seq, c.Send(models.MessageTypeInput, models.InputRequest{Seq: seq, Vx: &vx, Vy: &vy})
+}
+
+// Send sends any message, for the types without a wrapper
+func (c *Client) Send(msgType MessageType, payload any) error {
+	return c.send(models.Message{Type: msgType, Payload: payload})
+}
+
+// send encodes and writes one message
+func (c *Client) send(message models.Message) error {
+	data, err := c.codec.encode(message)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+	if err := c.conn.WriteMessage(c.codec.frameType(), data); err != nil {
+		return err
+	}
+	c.messagesOut.Add(1)
+	c.bytesOut.Add(uint64(len(data)))
+	return nil
+}
+
+// Events returns the events the server sends, in order. It is closed when
+// the connection ends.
+func (c *Client) Events() <-chan Event {
+	return c.events
+}
+
+// World returns the client's mirror of its room
+func (c *Client) World() *World {
+	return c.world
+}
+
+// Stats returns the connection's traffic so far
+func (c *Client) Stats() Stats {
+	return Stats{
+		MessagesIn:    c.messagesIn.Load(),
+		MessagesOut:   c.messagesOut.Load(),
+		BytesIn:       c.bytesIn.Load(),
+		BytesOut:      c.bytesOut.Load(),
+		DroppedEvents: c.dropped.Load(),
+		BadMessages:   c.badMessages.Load(),
+	}
+}
+
+// Done is closed when the connection ends
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the connection ended, once Done is closed. A close frame
+// from the server is a *websocket.CloseError carrying its code (e.g. 4001
+// kicked, 1001 server shutdown).
+func (c *Client) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// Close says goodbye to the server and waits for the connection to end
+func (c *Client) Close() error {
+	c.writeMu.Lock()
+	err := c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	c.writeMu.Unlock()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case <-c.done:
+	case <-timer.C:
+	}
+	c.conn.Close()
+	<-c.done
+	if errors.Is(err, websocket.ErrCloseSent) {
+		err = nil
+	}
+	return err
+}
+
+// readLoop reads until the connection ends, keeping the world in sync and
+// turning messages into events
+func (c *Client) readLoop() {
+	defer close(c.done)
+	defer close(c.events)
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				err = nil
+			}
+			c.err = err
+			return
+		}
+		c.messagesIn.Add(1)
+		c.bytesIn.Add(uint64(len(data)))
+
+		ev, err := c.handle(data)
+		if err != nil {
+			// 읽을 수 없는 메시지 하나 때문에 연결을 끊지는 않음
+			c.badMessages.Add(1)
+			continue
+		}
+		if ev != nil {
+			c.emit(ev)
+		}
+	}
+}
+
+// handle decodes one frame, applies it to the world and returns its event
+// (nil for a delta that can't be applied)
+func (c *Client) handle(data []byte) (Event, error) {
+	msgType, raw, err := c.codec.decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding message: %w", err)
+	}
+
+	switch msgType {
+	case models.MessageTypeWelcome:
+		var p models.WelcomePayload
+		if err := c.codec.payload(raw, &p); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", msgType, err)
+		}
+		c.world.welcome(p)
+		select {
+		case c.welcome <- p:
+		default:
+		}
+		return Welcome{p}, nil
+
+	case models.MessageTypeSpectate:
+		var p models.SpectatePayload
+		if err := c.codec.payload(raw, &p); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", msgType, err)
+		}
+		c.world.spectate(p)
+		return Spectate{p}, nil
+
+	case models.MessageTypeGameState:
+		var p models.GameStatePayload
+		if err := c.codec.payload(raw, &p); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", msgType, err)
+		}
+		state, ok := c.world.applyState(p)
+		if !ok {
+			return nil, nil
+		}
+		// 적용한 스냅샷을 알려 다음부터 이 스냅샷 대비 델타를 받음
+		if err := c.Send(models.MessageTypeStateAck, models.StateAck{Seq: p.Seq}); err != nil && !errors.Is(err, ErrClosed) {
+			return nil, fmt.Errorf("acknowledging snapshot %d: %w", p.Seq, err)
+		}
+		return GameState{GameStatePayload: p, State: state, Received: time.Now()}, nil
+
+	case models.MessageTypePlayerJoin, models.MessageTypePlayerEnter:
+		var p models.PlayerJoin
+		if err := c.codec.payload(raw, &p); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", msgType, err)
+		}
+		c.world.join(p)
+		return PlayerJoin{p}, nil
+
+	case models.MessageTypePlayerLeave, models.MessageTypePlayerExit:
+		var p models.PlayerLeave
+		if err := c.codec.payload(raw, &p); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", msgType, err)
+		}
+		c.world.leave(p.ID)
+		return PlayerLeave{p}, nil
+
+	case models.MessageTypePlayerMove:
+		var p models.PlayerMove
+		if err := c.codec.payload(raw, &p); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", msgType, err)
+		}
+		c.world.move(p)
+		return PlayerMove{p}, nil
+
+	case models.MessageTypeAnnouncement:
+		var p models.Announcement
+		if err := c.codec.payload(raw, &p); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", msgType, err)
+		}
+		return Announcement{p}, nil
+
+	case models.MessageTypeServerShutdown:
+		var p models.ServerShutdown
+		if err := c.codec.payload(raw, &p); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", msgType, err)
+		}
+		return ServerShutdown{p}, nil
+
+	case models.MessageTypeError:
+		var p models.ErrorPayload
+		if err := c.codec.payload(raw, &p); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", msgType, err)
+		}
+		if p.Type == models.MessageTypeLogin && p.ID != "" && p.ID == c.pendingLogin() {
+			select {
+			case c.refused <- Error{p}:
+			default:
+			}
+		}
+		return Error{p}, nil
+	}
+
+	var payload any
+	if len(raw) > 0 {
+		if err := c.codec.payload(raw, &payload); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", msgType, err)
+		}
+	}
+	return Other{Type: msgType, Payload: payload}, nil
+}
+
+// pendingLogin returns the id of the login Login is waiting on
+func (c *Client) pendingLogin() string {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+	return c.loginID
+}
+
+// emit hands an event to Events without ever blocking the read loop
+func (c *Client) emit(ev Event) {
+	select {
+	case c.events <- ev:
+	default:
+		c.dropped.Add(1)
+	}
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// fakeServer accepts one connection and hands each message the client sends
+// to reply, which answers with any number of messages
+func fakeServer(t *testing.T, reply func(msg models.Message, raw json.RawMessage) []models.Message) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{Subprotocols: []string{"json"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var in struct {
+				models.Message
+				Payload json.RawMessage `json:"payload"`
+			}
+			if err := conn.ReadJSON(&in); err != nil {
+				return
+			}
+			for _, out := range reply(in.Message, in.Payload) {
+				if err := conn.WriteJSON(out); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func connect(t *testing.T, url string) *Client {
+	t.Helper()
+	c, err := Connect(context.Background(), url, Options{Timeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestLoginIgnoresLateRefusalOfEarlierAttempt(t *testing.T) {
+	var first string
+	url := fakeServer(t, func(msg models.Message, _ json.RawMessage) []models.Message {
+		if msg.Type != models.MessageTypeLogin {
+			return nil
+		}
+		if first == "" {
+			// 첫 시도에는 답하지 않아 Login이 시간 초과로 끝남
+			first = msg.ID
+			return nil
+		}
+		// 두 번째 시도 직전에 첫 시도의 거절이 늦게 도착
+		return []models.Message{
+			{Type: models.MessageTypeError, Payload: models.ErrorPayload{Code: models.ErrorCodeDraining, Type: models.MessageTypeLogin, ID: first}},
+			{Type: models.MessageTypeWelcome, Payload: models.WelcomePayload{ID: "p1", Name: "bot"}},
+		}
+	})
+	c := connect(t, url)
+
+	if _, err := c.Login("bot", ""); !errors.Is(err, ErrNoWelcome) {
+		t.Fatalf("first login: %v, want %v", err, ErrNoWelcome)
+	}
+	w, err := c.Login("bot", "")
+	if err != nil {
+		t.Fatalf("second login took the earlier attempt's refusal: %v", err)
+	}
+	if w.ID != "p1" {
+		t.Errorf("welcome for %q, want p1", w.ID)
+	}
+}
+
+func TestLoginReturnsItsRefusal(t *testing.T) {
+	url := fakeServer(t, func(msg models.Message, _ json.RawMessage) []models.Message {
+		if msg.ID == "" {
+			t.Errorf("%s sent without an id", msg.Type)
+		}
+		return []models.Message{{Type: models.MessageTypeError, Payload: models.ErrorPayload{
+			Code: models.ErrorCodeRoomFull, Message: "room is full", Type: msg.Type, ID: msg.ID,
+		}}}
+	})
+	c := connect(t, url)
+
+	_, err := c.Login("bot", "")
+	var refused Error
+	if !errors.As(err, &refused) || refused.Code != models.ErrorCodeRoomFull {
+		t.Fatalf("login: %v, want a room_full Error", err)
+	}
+}
+
+func TestTypedServerEvents(t *testing.T) {
+	url := fakeServer(t, func(msg models.Message, _ json.RawMessage) []models.Message {
+		if msg.Type != models.MessageTypeSpectate {
+			return nil
+		}
+		return []models.Message{
+			{Type: models.MessageTypeSpectate, Payload: models.SpectatePayload{Room: "cup", Follow: "p1", Delay: 2000, World: models.DefaultWorldConfig()}},
+			{Type: models.MessageTypeAnnouncement, Payload: models.Announcement{Text: "maintenance in 5 minutes", SentAt: 1700000000000}},
+			{Type: models.MessageTypeServerShutdown, Payload: models.ServerShutdown{Seconds: 5, At: 1700000005000}},
+		}
+	})
+	c := connect(t, url)
+	if err := c.Send(models.MessageTypeSpectate, models.SpectateRequest{Follow: "p1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Event
+	timeout := time.After(time.Second)
+	for len(got) < 3 {
+		select {
+		case ev := <-c.Events():
+			got = append(got, ev)
+		case <-timeout:
+			t.Fatalf("got %d of 3 events", len(got))
+		}
+	}
+	if ev, ok := got[0].(Spectate); !ok || ev.Room != "cup" || ev.Follow != "p1" || ev.Delay != 2000 {
+		t.Errorf("event 0 = %#v, want Spectate of room cup following p1", got[0])
+	}
+	if ev, ok := got[1].(Announcement); !ok || ev.Text != "maintenance in 5 minutes" {
+		t.Errorf("event 1 = %#v, want Announcement", got[1])
+	}
+	if ev, ok := got[2].(ServerShutdown); !ok || ev.Seconds != 5 || ev.At != 1700000005000 {
+		t.Errorf("event 2 = %#v, want ServerShutdown in 5s", got[2])
+	}
+	if room, cfg := c.World().Room(), c.World().Config(); room != "cup" || cfg.Width != models.DefaultWorldConfig().Width {
+		t.Errorf("world mirrors room %q (%gx%g), want cup", room, cfg.Width, cfg.Height)
+	}
+}
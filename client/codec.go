@@ -0,0 +1,89 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fasthttp/websocket"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// codec is the client side of a wire encoding the server speaks (see
+// ws.Codec). Payloads stay raw until the message type says what to decode
+// them into.
+type codec interface {
+	name() string
+	frameType() int
+	encode(message models.Message) ([]byte, error)
+	decode(data []byte) (models.MessageType, []byte, error)
+	payload(raw []byte, v any) error
+}
+
+// newCodec returns the codec called name ("" is JSON)
+func newCodec(name string) (codec, error) {
+	switch name {
+	case "", "json":
+		return jsonCodec{}, nil
+	case "msgpack":
+		return msgpackCodec{}, nil
+	}
+	return nil, fmt.Errorf("unknown encoding %q", name)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) name() string   { return "json" }
+func (jsonCodec) frameType() int { return websocket.TextMessage }
+
+func (jsonCodec) encode(message models.Message) ([]byte, error) {
+	return json.Marshal(message)
+}
+
+func (jsonCodec) decode(data []byte) (models.MessageType, []byte, error) {
+	var envelope struct {
+		Type    models.MessageType `json:"type"`
+		Payload json.RawMessage    `json:"payload"`
+	}
+	err := json.Unmarshal(data, &envelope)
+	return envelope.Type, envelope.Payload, err
+}
+
+func (jsonCodec) payload(raw []byte, v any) error {
+	return json.Unmarshal(raw, v)
+}
+
+// msgpackCodec matches the server's: field names follow the json tags
+type msgpackCodec struct{}
+
+func (msgpackCodec) name() string   { return "msgpack" }
+func (msgpackCodec) frameType() int { return websocket.BinaryMessage }
+
+func (msgpackCodec) encode(message models.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	enc.UseCompactFloats(true)
+	if err := enc.Encode(message); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c msgpackCodec) decode(data []byte) (models.MessageType, []byte, error) {
+	var envelope struct {
+		Type    models.MessageType `json:"type"`
+		Payload msgpack.RawMessage `json:"payload"`
+	}
+	err := c.payload(data, &envelope)
+	return envelope.Type, envelope.Payload, err
+}
+
+func (msgpackCodec) payload(raw []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(raw))
+	dec.SetCustomStructTag("json")
+	dec.UseLooseInterfaceDecoding(true)
+	return dec.Decode(v)
+}
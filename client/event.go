@@ -0,0 +1,97 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/interp"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// Event is something the server told the client. Switch on the concrete
+// type: Welcome, Spectate, GameState, PlayerJoin, PlayerLeave, PlayerMove,
+// Announcement, ServerShutdown, Error or Other.
+type Event interface {
+	event()
+}
+
+// Welcome is the server's reply to a login (or a resume)
+type Welcome struct {
+	WelcomePayload
+}
+
+// Spectate is the server's reply to a spectate message, or the first
+// message on a replay connection
+type Spectate struct {
+	SpectatePayload
+}
+
+// GameState is one game_state, full or delta, with the whole world it
+// describes. Deltas whose baseline the client no longer has are dropped
+// without an event, like public/index.html does.
+type GameState struct {
+	GameStatePayload
+	State    map[string]PlayerState // 델타를 적용한 모든 플레이어 (ID -> 상태)
+	Received time.Time              // 받은 시각 (클라이언트 시계)
+}
+
+// Snapshot returns the state as a Snapshot to add to an interpolation Buffer
+func (s GameState) Snapshot() Snapshot {
+	return interp.FromPayload(s.GameStatePayload, s.State)
+}
+
+// PlayerJoin is a player_join, or a player_enter when the server filters by
+// area of interest
+type PlayerJoin struct {
+	models.PlayerJoin
+}
+
+// PlayerLeave is a player_leave, or a player_exit when the server filters
+// by area of interest
+type PlayerLeave struct {
+	models.PlayerLeave
+}
+
+// PlayerMove is a player_move (a teleport)
+type PlayerMove struct {
+	models.PlayerMove
+}
+
+// Announcement is a message from the server operators
+type Announcement struct {
+	models.Announcement
+}
+
+// ServerShutdown warns that the server closes the connection at At
+type ServerShutdown struct {
+	models.ServerShutdown
+}
+
+// Error is the server refusing one of the client's messages; Code says why
+// and ID is the id the message was sent with, if any. It is also an error,
+// so Login can return it.
+type Error struct {
+	ErrorPayload
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("server: %s (%s)", e.Message, e.Code)
+}
+
+// Other is any other message, with its payload decoded into plain maps,
+// slices and numbers
+type Other struct {
+	Type    MessageType
+	Payload any
+}
+
+func (Welcome) event()        {}
+func (Spectate) event()       {}
+func (GameState) event()      {}
+func (PlayerJoin) event()     {}
+func (PlayerLeave) event()    {}
+func (PlayerMove) event()     {}
+func (Announcement) event()   {}
+func (ServerShutdown) event() {}
+func (Error) event()          {}
+func (Other) event()          {}
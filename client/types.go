@@ -0,0 +1,109 @@
+package client
+
+import (
+	"github.com/sangjinsu/websocket-multiplayer/internal/interp"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 서버와 주고받는 타입. internal 패키지는 이 모듈 밖에서 import할 수 없으므로
+// 별칭으로 다시 내보낸다 (models의 같은 타입과 그대로 호환)
+
+// Payloads the server sends, embedded in the matching events, and the world
+// state built from them
+type (
+	WelcomePayload        = models.WelcomePayload
+	SpectatePayload       = models.SpectatePayload
+	GameStatePayload      = models.GameStatePayload
+	PlayerJoinPayload     = models.PlayerJoin
+	PlayerLeavePayload    = models.PlayerLeave
+	PlayerMovePayload     = models.PlayerMove
+	AnnouncementPayload   = models.Announcement
+	ServerShutdownPayload = models.ServerShutdown
+	ErrorPayload          = models.ErrorPayload
+	PlayerState           = models.PlayerState
+	PlayerDelta           = models.PlayerDelta
+	WorldConfig           = models.WorldConfig
+)
+
+// Payloads a client can send with Send
+type (
+	PlayerLogin          = models.PlayerLogin
+	Point                = models.Point
+	InputRequest         = models.InputRequest
+	JoinRoomRequest      = models.JoinRoomRequest
+	ReconnectRequest     = models.ReconnectRequest
+	SpectateRequest      = models.SpectateRequest
+	ChatRequest          = models.ChatRequest
+	ReplayControlRequest = models.ReplayControlRequest
+	StateAck             = models.StateAck
+)
+
+// MessageType is the type of a message on the wire
+type MessageType = models.MessageType
+
+const (
+	MessageTypeWelcome        = models.MessageTypeWelcome
+	MessageTypeGameState      = models.MessageTypeGameState
+	MessageTypePlayerJoin     = models.MessageTypePlayerJoin
+	MessageTypePlayerLeave    = models.MessageTypePlayerLeave
+	MessageTypePlayerMove     = models.MessageTypePlayerMove
+	MessageTypeMove           = models.MessageTypeMove
+	MessageTypeReconnect      = models.MessageTypeReconnect
+	MessageTypeLogin          = models.MessageTypeLogin
+	MessageTypeCollision      = models.MessageTypeCollision
+	MessageTypeInput          = models.MessageTypeInput
+	MessageTypeJoinRoom       = models.MessageTypeJoinRoom
+	MessageTypeStateAck       = models.MessageTypeStateAck
+	MessageTypePlayerEnter    = models.MessageTypePlayerEnter
+	MessageTypePlayerExit     = models.MessageTypePlayerExit
+	MessageTypeRoundPhase     = models.MessageTypeRoundPhase
+	MessageTypeChat           = models.MessageTypeChat
+	MessageTypeChatHistory    = models.MessageTypeChatHistory
+	MessageTypeSpectate       = models.MessageTypeSpectate
+	MessageTypeAnnouncement   = models.MessageTypeAnnouncement
+	MessageTypeServerShutdown = models.MessageTypeServerShutdown
+	MessageTypeReplayControl  = models.MessageTypeReplayControl
+	MessageTypeReplayStatus   = models.MessageTypeReplayStatus
+	MessageTypeError          = models.MessageTypeError
+)
+
+// ErrorCode says why the server refused a message (Error.Code)
+type ErrorCode = models.ErrorCode
+
+const (
+	ErrorCodeBadMessage      = models.ErrorCodeBadMessage
+	ErrorCodeUnknownType     = models.ErrorCodeUnknownType
+	ErrorCodeInvalidPayload  = models.ErrorCodeInvalidPayload
+	ErrorCodeNotLoggedIn     = models.ErrorCodeNotLoggedIn
+	ErrorCodeAlreadyLoggedIn = models.ErrorCodeAlreadyLoggedIn
+	ErrorCodeRoomFull        = models.ErrorCodeRoomFull
+	ErrorCodeDraining        = models.ErrorCodeDraining
+	ErrorCodeReplayRoom      = models.ErrorCodeReplayRoom
+	ErrorCodeNotReplay       = models.ErrorCodeNotReplay
+	ErrorCodeInvalidToken    = models.ErrorCodeInvalidToken
+	ErrorCodePlayerNotFound  = models.ErrorCodePlayerNotFound
+	ErrorCodeRateLimited     = models.ErrorCodeRateLimited
+	ErrorCodeFiltered        = models.ErrorCodeFiltered
+	ErrorCodeInternal        = models.ErrorCodeInternal
+)
+
+// ChatChannel is where a ChatRequest goes
+type ChatChannel = models.ChatChannel
+
+const (
+	ChatGlobal = models.ChatGlobal
+	ChatRoom   = models.ChatRoom
+	ChatDirect = models.ChatDirect
+)
+
+// Interpolation of received snapshots (see GameState.Snapshot)
+type (
+	Snapshot = interp.Snapshot
+	Position = interp.Position
+	Buffer   = interp.Buffer
+)
+
+// NewBuffer creates an interpolation buffer holding at most size snapshots
+func NewBuffer(size int) *Buffer {
+	return interp.NewBuffer(size)
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 델타의 baseline으로 보관하는 최근 스냅샷 수 (public/index.html과 같음)
+const snapshotHistory = 128
+
+// World is the client's mirror of the room it is in, kept in sync from
+// welcome, game_state, player_join, player_leave and player_move. It is safe
+// to read from any goroutine while the client runs.
+type World struct {
+	mu         sync.RWMutex
+	self       string
+	room       string
+	config     models.WorldConfig
+	players    map[string]models.PlayerState
+	tick       uint64
+	serverTime time.Time
+	snapshots  map[uint32]map[string]models.PlayerState // seq -> 스냅샷 (델타 baseline)
+}
+
+func newWorld() *World {
+	return &World{
+		players:   make(map[string]models.PlayerState),
+		snapshots: make(map[uint32]map[string]models.PlayerState),
+	}
+}
+
+// SelfID returns the ID of the client's player, or "" before login
+func (w *World) SelfID() string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.self
+}
+
+// Self returns the client's own player
+func (w *World) Self() (PlayerState, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	p, ok := w.players[w.self]
+	return p, ok && w.self != ""
+}
+
+// Player returns one player
+func (w *World) Player(id string) (PlayerState, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	p, ok := w.players[id]
+	return p, ok
+}
+
+// Players returns a copy of every player
+func (w *World) Players() map[string]PlayerState {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return maps.Clone(w.players)
+}
+
+// Room returns the room the client is in, as of the last welcome or
+// spectate reply
+func (w *World) Room() string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.room
+}
+
+// Config returns the world config the server sent in welcome or spectate
+func (w *World) Config() WorldConfig {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.config
+}
+
+// Tick returns the server tick and time of the last game_state
+func (w *World) Tick() (uint64, time.Time) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.tick, w.serverTime
+}
+
+// welcome starts mirroring the room the welcome is for
+func (w *World) welcome(p models.WelcomePayload) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if p.Room != w.room {
+		// 다른 방: 이전 방의 플레이어와 스냅샷은 쓸모 없음
+		clear(w.players)
+		clear(w.snapshots)
+	}
+	w.self, w.room, w.config = p.ID, p.Room, p.World
+}
+
+// spectate starts mirroring the room the client now watches, without a
+// player of its own
+func (w *World) spectate(p models.SpectatePayload) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if p.Room != w.room {
+		clear(w.players)
+		clear(w.snapshots)
+	}
+	w.self, w.room, w.config = "", p.Room, p.World
+}
+
+// applyState applies a game_state and returns the world it describes, or
+// false for a delta against a snapshot the client doesn't have
+func (w *World) applyState(p models.GameStatePayload) (map[string]models.PlayerState, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var players map[string]models.PlayerState
+	if p.Full {
+		players = maps.Clone(p.Players)
+		if players == nil {
+			players = make(map[string]models.PlayerState)
+		}
+	} else {
+		base, ok := w.snapshots[p.Baseline]
+		if !ok {
+			return nil, false
+		}
+		players = maps.Clone(base)
+		for id, d := range p.Changed {
+			prev, ok := players[id]
+			if !ok {
+				prev = models.PlayerState{ID: id}
+			}
+			players[id] = prev.Apply(d)
+		}
+		for _, id := range p.Removed {
+			delete(players, id)
+		}
+	}
+
+	w.snapshots[p.Seq] = players
+	for seq := range w.snapshots {
+		if p.Seq-seq >= snapshotHistory && seq < p.Seq {
+			delete(w.snapshots, seq)
+		}
+	}
+	w.players = maps.Clone(players)
+	w.tick, w.serverTime = p.Tick, p.Time()
+	return maps.Clone(players), true
+}
+
+// join adds a player announced by player_join or player_enter
+func (w *World) join(p models.PlayerJoin) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	st := w.players[p.ID]
+	st.ID, st.PlayerNum, st.Name, st.Color, st.Bot = p.ID, p.PlayerNum, p.Name, p.Color, p.Bot
+	st.X, st.Y = p.X, p.Y
+	w.players[p.ID] = st
+}
+
+// leave removes a player announced by player_leave or player_exit
+func (w *World) leave(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.players, id)
+}
+
+// move puts a player where player_move says it is
+func (w *World) move(p models.PlayerMove) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if st, ok := w.players[p.ID]; ok {
+		st.X, st.Y = p.X, p.Y
+		w.players[p.ID] = st
+	}
+}
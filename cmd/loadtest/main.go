@@ -0,0 +1,300 @@
+// Command loadtest opens many websocket connections to a running server,
+// logs each one in and has it send random WASD or velocity input, then
+// reports how the server kept up:
+//
+//	go run ./cmd/loadtest -clients 1000 -rate 10 -duration 30s
+//	go run ./cmd/loadtest -url ws://localhost:3000/ws -clients 2000 -rooms 20 -encoding msgpack
+//
+// Thousands of connections from one machine need a high enough open file
+// limit on both ends (ulimit -n).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/client"
+)
+
+type config struct {
+	url      string
+	clients  int
+	rooms    int
+	room     string
+	encoding string
+	rate     float64
+	velocity float64
+	duration time.Duration
+	ramp     time.Duration
+	report   time.Duration
+}
+
+func main() {
+	var cfg config
+	flag.StringVar(&cfg.url, "url", "ws://localhost:3000/ws", "server websocket endpoint")
+	flag.IntVar(&cfg.clients, "clients", 100, "number of concurrent connections")
+	flag.IntVar(&cfg.rooms, "rooms", 1, "spread the clients over this many rooms")
+	flag.StringVar(&cfg.room, "room", "loadtest", "room ID, or room ID prefix with -rooms > 1")
+	flag.StringVar(&cfg.encoding, "encoding", "json", "wire encoding: json or msgpack")
+	flag.Float64Var(&cfg.rate, "rate", 10, "inputs per second per client")
+	flag.Float64Var(&cfg.velocity, "velocity", 0.5, "share of inputs sent as velocity instead of a WASD key (0-1)")
+	flag.DurationVar(&cfg.duration, "duration", 30*time.Second, "how long to send input once every client is connected")
+	flag.DurationVar(&cfg.ramp, "ramp", 5*time.Second, "spread the connects over this long")
+	flag.DurationVar(&cfg.report, "report", 5*time.Second, "progress report interval (0 = off)")
+	flag.Parse()
+
+	if cfg.clients <= 0 || cfg.rooms <= 0 || cfg.rate <= 0 || cfg.velocity < 0 || cfg.velocity > 1 {
+		log.Fatal("clients, rooms and rate must be positive and velocity between 0 and 1")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	run := newRun(cfg)
+	run.start(ctx)
+	run.summary(os.Stdout)
+}
+
+// run is one load test: every simulated client and what they measured
+type run struct {
+	cfg     config
+	clients []*sim
+	wg      sync.WaitGroup
+
+	connected, connectFailed atomic.Int64
+	loggedIn, loginFailed    atomic.Int64
+	disconnected             atomic.Int64
+	sending                  time.Time // 모든 연결이 끝나고 입력을 보내기 시작한 시각
+	ended                    time.Time
+}
+
+func newRun(cfg config) *run {
+	r := &run{cfg: cfg, clients: make([]*sim, cfg.clients)}
+	for i := range r.clients {
+		room := cfg.room
+		if cfg.rooms > 1 {
+			room = fmt.Sprintf("%s-%d", cfg.room, i%cfg.rooms)
+		}
+		r.clients[i] = &sim{run: r, n: i, room: room, rng: rand.New(rand.NewSource(int64(i)))}
+	}
+	return r
+}
+
+// start connects every client over the ramp, lets them play for the
+// duration and disconnects them
+func (r *run) start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	play := make(chan struct{}) // 모두 연결되면 닫힘
+
+	log.Printf("Connecting %d clients to %s over %v", r.cfg.clients, r.cfg.url, r.cfg.ramp)
+	stopReport := r.reportProgress(ctx)
+	spacing := r.cfg.ramp / time.Duration(r.cfg.clients)
+	for _, s := range r.clients {
+		r.wg.Add(1)
+		go s.play(ctx, play)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(spacing):
+		}
+	}
+
+	// 마지막 연결이 끝나기를 잠깐 기다린 뒤 측정 시작
+	deadline := time.Now().Add(client.DefaultTimeout)
+	for r.connected.Load()+r.connectFailed.Load() < int64(r.cfg.clients) && time.Now().Before(deadline) && ctx.Err() == nil {
+		time.Sleep(50 * time.Millisecond)
+	}
+	r.sending = time.Now()
+	close(play)
+	log.Printf("%d/%d clients logged in, sending input for %v", r.loggedIn.Load(), r.cfg.clients, r.cfg.duration)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(r.cfg.duration):
+	}
+	r.ended = time.Now()
+	cancel()
+	stopReport()
+	r.wg.Wait()
+}
+
+// reportProgress logs the connection count and receive rate every report
+// interval until the returned func is called
+func (r *run) reportProgress(ctx context.Context) func() {
+	if r.cfg.report <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(r.cfg.report)
+		defer ticker.Stop()
+		var lastMessages uint64
+		last := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case now := <-ticker.C:
+				var messages uint64
+				for _, s := range r.clients {
+					messages += s.messagesIn.Load()
+				}
+				rate := float64(messages-lastMessages) / now.Sub(last).Seconds()
+				log.Printf("connected %d, logged in %d, disconnected %d, receiving %.0f msg/s",
+					r.connected.Load(), r.loggedIn.Load(), r.disconnected.Load(), rate)
+				lastMessages, last = messages, now
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// sim is one simulated player
+type sim struct {
+	run  *run
+	n    int
+	room string
+	rng  *rand.Rand
+
+	connectTime time.Duration
+	loginTime   time.Duration
+	base        client.Stats  // 측정을 시작할 때의 트래픽
+	stats       client.Stats  // 연결이 끝났을 때의 트래픽
+	active      time.Duration // 측정한 시간
+	messagesIn  atomic.Uint64 // 받은 이벤트 수 (진행 보고용)
+
+	pending   []sent          // 서버가 아직 처리하지 않은 입력 (seq 순)
+	latencies []time.Duration // 입력 → 그 입력을 반영한 game_state 수신
+	gaps      []time.Duration // 연속된 game_state 사이 간격
+	lastState time.Time
+}
+
+// sent is an input waiting for the server to process it
+type sent struct {
+	seq uint32
+	at  time.Time
+}
+
+// play connects, logs in and, once start is closed, sends input and
+// measures until ctx ends
+func (s *sim) play(ctx context.Context, start <-chan struct{}) {
+	defer s.run.wg.Done()
+
+	begin := time.Now()
+	c, err := client.Connect(ctx, s.run.cfg.url, client.Options{Room: s.room, Encoding: s.run.cfg.encoding})
+	if err != nil {
+		s.run.connectFailed.Add(1)
+		if s.run.connectFailed.Load() <= 5 {
+			log.Printf("Client %d: %v", s.n, err)
+		}
+		return
+	}
+	s.connectTime = time.Since(begin)
+	s.run.connected.Add(1)
+	defer func() {
+		s.stats = c.Stats()
+		c.Close()
+	}()
+
+	begin = time.Now()
+	welcome, err := c.Login(fmt.Sprintf("load-%d", s.n), "")
+	if err != nil {
+		s.run.loginFailed.Add(1)
+		if s.run.loginFailed.Load() <= 5 {
+			log.Printf("Client %d login: %v", s.n, err)
+		}
+		return
+	}
+	s.loginTime = time.Since(begin)
+	s.run.loggedIn.Add(1)
+
+	// 연결하는 동안에도 이벤트는 계속 비워 둠 (측정은 start 이후부터)
+	measuring := false
+	interval := time.Duration(float64(time.Second) / s.run.cfg.rate)
+	ticker := time.NewTicker(interval)
+	ticker.Stop()
+	var began time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			if measuring {
+				s.active = time.Since(began)
+			}
+			return
+
+		case <-start:
+			start = nil
+			measuring = true
+			began = time.Now()
+			s.base = c.Stats()
+			// 클라이언트마다 시작 위상을 흩어서 입력이 한 tick에 몰리지 않게
+			time.Sleep(time.Duration(s.rng.Int63n(int64(interval))))
+			ticker.Reset(interval)
+
+		case <-ticker.C:
+			seq, err := s.sendInput(c, welcome.World.MaxSpeed)
+			if err != nil {
+				continue
+			}
+			s.pending = append(s.pending, sent{seq: seq, at: time.Now()})
+
+		case ev, ok := <-c.Events():
+			if !ok {
+				s.run.disconnected.Add(1)
+				if measuring {
+					s.active = time.Since(began)
+				}
+				return
+			}
+			s.messagesIn.Add(1)
+			if st, ok := ev.(client.GameState); ok && measuring {
+				s.observe(st, welcome.ID)
+			}
+		}
+	}
+}
+
+// sendInput sends a random WASD key or velocity
+func (s *sim) sendInput(c *client.Client, maxSpeed float64) (uint32, error) {
+	if s.rng.Float64() < s.run.cfg.velocity {
+		angle := s.rng.Float64() * 2 * math.Pi
+		speed := s.rng.Float64() * maxSpeed
+		return c.SendVelocity(math.Cos(angle)*speed, math.Sin(angle)*speed)
+	}
+	return c.SendKey(string("wasd"[s.rng.Intn(4)]))
+}
+
+// observe records a game_state's arrival gap and the inputs it confirms
+func (s *sim) observe(st client.GameState, self string) {
+	if !s.lastState.IsZero() {
+		s.gaps = append(s.gaps, st.Received.Sub(s.lastState))
+	}
+	s.lastState = st.Received
+
+	me, ok := st.State[self]
+	if !ok {
+		return
+	}
+	done := 0
+	for _, p := range s.pending {
+		if p.seq > me.LastInputSeq {
+			break
+		}
+		s.latencies = append(s.latencies, st.Received.Sub(p.at))
+		done++
+	}
+	s.pending = s.pending[done:]
+}
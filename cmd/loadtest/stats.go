@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+)
+
+// summary prints what the run measured
+func (r *run) summary(w io.Writer) {
+	var connects, logins, latencies, gaps []time.Duration
+	var bytesIn, bytesOut, messagesIn []float64 // 클라이언트별 초당 값
+	var dropped, bad uint64
+	unconfirmed := 0
+	for _, s := range r.clients {
+		if s.connectTime > 0 {
+			connects = append(connects, s.connectTime)
+		}
+		if s.loginTime > 0 {
+			logins = append(logins, s.loginTime)
+		}
+		latencies = append(latencies, s.latencies...)
+		gaps = append(gaps, s.gaps...)
+		unconfirmed += len(s.pending)
+		dropped += s.stats.DroppedEvents
+		bad += s.stats.BadMessages
+		if s.active > 0 {
+			bytesIn = append(bytesIn, perSecond(s.base.BytesIn, s.stats.BytesIn, s.active))
+			bytesOut = append(bytesOut, perSecond(s.base.BytesOut, s.stats.BytesOut, s.active))
+			messagesIn = append(messagesIn, perSecond(s.base.MessagesIn, s.stats.MessagesIn, s.active))
+		}
+	}
+
+	fmt.Fprintf(w, "\nConnections (%s, %d clients in %d room(s), %s)\n", r.cfg.url, r.cfg.clients, r.cfg.rooms, r.cfg.encoding)
+	fmt.Fprintf(w, "  connected       %d/%d (%.1f%%), %d failed\n", r.connected.Load(), r.cfg.clients,
+		100*float64(r.connected.Load())/float64(r.cfg.clients), r.connectFailed.Load())
+	fmt.Fprintf(w, "  logged in       %d, %d failed\n", r.loggedIn.Load(), r.loginFailed.Load())
+	fmt.Fprintf(w, "  dropped         %d by the server during the test\n", r.disconnected.Load())
+	fmt.Fprintf(w, "  connect time    %s\n", durations(connects))
+	fmt.Fprintf(w, "  login time      %s\n", durations(logins))
+
+	fmt.Fprintf(w, "\nTraffic per client over %v (%.0f inputs/s each)\n", r.ended.Sub(r.sending).Round(time.Millisecond), r.cfg.rate)
+	fmt.Fprintf(w, "  messages in/s   %s (all clients %.0f/s)\n", numbers(messagesIn, ""), sum(messagesIn))
+	fmt.Fprintf(w, "  bytes in/s      %s\n", numbers(bytesIn, "B"))
+	fmt.Fprintf(w, "  bytes out/s     %s\n", numbers(bytesOut, "B"))
+	fmt.Fprintf(w, "  events dropped  %d (client buffer full), %d undecodable\n", dropped, bad)
+
+	fmt.Fprintf(w, "\ngame_state inter-arrival (%d gaps)\n", len(gaps))
+	fmt.Fprintf(w, "  interval        %s\n", durations(gaps))
+	fmt.Fprintf(w, "  jitter          %v (standard deviation)\n", stddev(gaps).Round(time.Microsecond))
+
+	fmt.Fprintf(w, "\nInput latency: input sent -> first game_state with it applied (%d inputs, %d never confirmed)\n", len(latencies), unconfirmed)
+	fmt.Fprintf(w, "  latency         %s\n", durations(latencies))
+}
+
+// perSecond returns how fast a counter went from start to end over d
+func perSecond(start, end uint64, d time.Duration) float64 {
+	return float64(end-start) / d.Seconds()
+}
+
+// percentile returns the p-th percentile (0-1) of sorted values
+func percentile[T any](sorted []T, p float64) T {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+// durations formats the spread of a set of durations
+func durations(d []time.Duration) string {
+	if len(d) == 0 {
+		return "-"
+	}
+	sorted := slices.Clone(d)
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, v := range sorted {
+		total += v
+	}
+	round := func(v time.Duration) time.Duration { return v.Round(time.Microsecond) }
+	return fmt.Sprintf("mean %v  p50 %v  p90 %v  p99 %v  max %v",
+		round(total/time.Duration(len(sorted))), round(percentile(sorted, 0.5)),
+		round(percentile(sorted, 0.9)), round(percentile(sorted, 0.99)), round(sorted[len(sorted)-1]))
+}
+
+// numbers formats the spread of a set of per-client rates
+func numbers(v []float64, unit string) string {
+	if len(v) == 0 {
+		return "-"
+	}
+	sorted := slices.Clone(v)
+	slices.Sort(sorted)
+	f := func(x float64) string {
+		switch {
+		case unit == "B" && x >= 1<<20:
+			return fmt.Sprintf("%.1fMiB", x/(1<<20))
+		case unit == "B" && x >= 1<<10:
+			return fmt.Sprintf("%.1fKiB", x/(1<<10))
+		}
+		return fmt.Sprintf("%.1f%s", x, unit)
+	}
+	return fmt.Sprintf("mean %s  min %s  p50 %s  p99 %s  max %s",
+		f(sum(sorted)/float64(len(sorted))), f(sorted[0]), f(percentile(sorted, 0.5)),
+		f(percentile(sorted, 0.99)), f(sorted[len(sorted)-1]))
+}
+
+func sum(v []float64) float64 {
+	total := 0.0
+	for _, x := range v {
+		total += x
+	}
+	return total
+}
+
+// stddev returns the standard deviation of d
+func stddev(d []time.Duration) time.Duration {
+	if len(d) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, v := range d {
+		mean += float64(v)
+	}
+	mean /= float64(len(d))
+	var variance float64
+	for _, v := range d {
+		variance += (float64(v) - mean) * (float64(v) - mean)
+	}
+	return time.Duration(math.Sqrt(variance / float64(len(d)-1)))
+}
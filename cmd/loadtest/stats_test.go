@@ -0,0 +1,108 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	ten := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		name   string
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{"p50 of ten", ten, 0.5, 5},
+		{"p90 of ten", ten, 0.9, 9},
+		{"p99 of ten", ten, 0.99, 10},
+		{"max", ten, 1, 10},
+		{"p0 is the min", ten, 0, 1},
+		{"one value", []time.Duration{7}, 0.5, 7},
+		{"p50 rounds up", []time.Duration{1, 2, 3}, 0.5, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != tt.want {
+				t.Errorf("percentile(%v, %g) = %v, want %v", tt.sorted, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurations(t *testing.T) {
+	tests := []struct {
+		name string
+		d    []time.Duration
+		want string
+	}{
+		{"none", nil, "-"},
+		{"unsorted", []time.Duration{30 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond},
+			"mean 20ms  p50 20ms  p90 30ms  p99 30ms  max 30ms"},
+		{"rounded to µs", []time.Duration{1500 * time.Nanosecond, 2500 * time.Nanosecond},
+			"mean 2µs  p50 2µs  p90 3µs  p99 3µs  max 3µs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := durations(tt.d); got != tt.want {
+				t.Errorf("durations(%v)\n got %q\nwant %q", tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRates(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end uint64
+		d          time.Duration
+		want       float64
+	}{
+		{"per second", 100, 700, 2 * time.Second, 300},
+		{"under a second", 0, 30, 500 * time.Millisecond, 60},
+		{"idle", 42, 42, time.Second, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := perSecond(tt.start, tt.end, tt.d); got != tt.want {
+				t.Errorf("perSecond(%d, %d, %v) = %g, want %g", tt.start, tt.end, tt.d, got, tt.want)
+			}
+		})
+	}
+
+	// 클라이언트별 초당 값의 분포
+	formats := []struct {
+		name string
+		v    []float64
+		unit string
+		want string
+	}{
+		{"none", nil, "", "-"},
+		{"messages", []float64{60, 20, 40}, "", "mean 40.0  min 20.0  p50 40.0  p99 60.0  max 60.0"},
+		{"bytes", []float64{512, 2048, 3 << 20}, "B", "mean 1.0MiB  min 512.0B  p50 2.0KiB  p99 3.0MiB  max 3.0MiB"},
+	}
+	for _, tt := range formats {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := numbers(tt.v, tt.unit); got != tt.want {
+				t.Errorf("numbers(%v, %q)\n got %q\nwant %q", tt.v, tt.unit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStddev(t *testing.T) {
+	tests := []struct {
+		d    []time.Duration
+		want time.Duration
+	}{
+		{nil, 0},
+		{[]time.Duration{time.Second}, 0},
+		{[]time.Duration{16 * time.Millisecond, 16 * time.Millisecond}, 0},
+		{[]time.Duration{2, 4, 4, 4, 5, 5, 7, 9}, 2}, // 표본 표준편차 약 2.14
+	}
+	for _, tt := range tests {
+		if got := stddev(tt.d); got != tt.want {
+			t.Errorf("stddev(%v) = %v, want %v", tt.d, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// Package admin is the REST API operators use to inspect and control the
+// live server: list players, kick and ban them, teleport them, announce,
+// pause rooms, dump their state, record replays, set bot populations and
+// drain the server before a restart.
+// Every request needs the admin token.
+package admin
+
+import (
+	"crypto/subtle"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	ws "github.com/sangjinsu/websocket-multiplayer/internal/websocket"
+)
+
+// API serves /admin on top of the room manager
+type API struct {
+	rooms *ws.RoomManager
+	token string
+}
+
+// New creates the admin API; requests must carry token
+func New(rooms *ws.RoomManager, token string) *API {
+	return &API{rooms: rooms, token: token}
+}
+
+// Register mounts the API under /admin
+func (a *API) Register(app *fiber.App) {
+	r := app.Group("/admin", a.auth)
+
+	r.Get("/players", a.players)
+	r.Post("/players/:id/kick", a.kick)
+	r.Post("/players/:id/ban", a.banPlayer)
+	r.Post("/players/:id/teleport", a.teleport)
+
+	r.Post("/ips/:ip/kick", a.kickIP)
+
+	r.Get("/bans", a.bans)
+	r.Post("/bans", a.ban)
+	r.Delete("/bans/:ip", a.unban)
+
+	r.Post("/announce", a.announce)
+
+	r.Get("/drain", a.drainStatus)
+	r.Post("/drain", a.drain)
+
+	r.Get("/rooms", a.roomList)
+	r.Post("/rooms/:room/pause", a.pause(true))
+	r.Post("/rooms/:room/resume", a.pause(false))
+	r.Get("/rooms/:room/state", a.state)
+	r.Post("/rooms/:room/record", a.record)
+	r.Delete("/rooms/:room/record", a.stopRecording)
+	r.Post("/rooms/:room/bots", a.bots)
+
+	r.Get("/replays", a.replays)
+	r.Get("/replays/:name", a.downloadReplay)
+}
+
+// auth accepts "Authorization: Bearer <token>" or "X-Admin-Token: <token>"
+func (a *API) auth(c *fiber.Ctx) error {
+	token := c.Get("X-Admin-Token")
+	if bearer, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+		token = bearer
+	}
+	if a.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+		return fail(c, fiber.StatusUnauthorized, "invalid admin token")
+	}
+	return c.Next()
+}
+
+// fail writes an error response: {"error": msg}
+func fail(c *fiber.Ctx, status int, msg string) error {
+	return c.Status(status).JSON(fiber.Map{"error": msg})
+}
+
+// failErr maps errors from the room manager to a status code
+func failErr(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, ws.ErrPlayerNotFound), errors.Is(err, ws.ErrRoomNotFound), errors.Is(err, ws.ErrNoConnections):
+		return fail(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, ws.ErrBadPosition):
+		return fail(c, fiber.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, ws.ErrReplayNotFound), errors.Is(err, ws.ErrReplaysDisabled):
+		return fail(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, ws.ErrRecording), errors.Is(err, ws.ErrNotRecording), errors.Is(err, ws.ErrReplayRoom):
+		return fail(c, fiber.StatusConflict, err.Error())
+	}
+	return fail(c, fiber.StatusInternalServerError, err.Error())
+}
+
+// GET /admin/players
+func (a *API) players(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"players": a.rooms.Players()})
+}
+
+type reasonBody struct {
+	Reason string `json:"reason"`
+}
+
+// reason reads the optional {"reason": ...} body, defaulting to def
+func reason(c *fiber.Ctx, def string) string {
+	var body reasonBody
+	if len(c.Body()) > 0 && c.BodyParser(&body) == nil && body.Reason != "" {
+		return body.Reason
+	}
+	return def
+}
+
+// POST /admin/players/:id/kick {"reason": "..."}
+func (a *API) kick(c *fiber.Ctx) error {
+	if err := a.rooms.Kick(c.Params("id"), reason(c, "kicked by admin")); err != nil {
+		return failErr(c, err)
+	}
+	return c.JSON(fiber.Map{"kicked": c.Params("id")})
+}
+
+// POST /admin/players/:id/ban {"reason": "..."}
+func (a *API) banPlayer(c *fiber.Ctx) error {
+	ip, err := a.rooms.BanPlayer(c.Params("id"), reason(c, "banned by admin"))
+	if err != nil {
+		return failErr(c, err)
+	}
+	return c.JSON(fiber.Map{"banned": ip})
+}
+
+// POST /admin/players/:id/teleport {"x": 100, "y": 200}
+func (a *API) teleport(c *fiber.Ctx) error {
+	var body struct {
+		X *float64 `json:"x"`
+		Y *float64 `json:"y"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.X == nil || body.Y == nil {
+		return fail(c, fiber.StatusBadRequest, "body must be {\"x\": number, \"y\": number}")
+	}
+	state, err := a.rooms.Teleport(c.Params("id"), *body.X, *body.Y)
+	if err != nil {
+		return failErr(c, err)
+	}
+	return c.JSON(state)
+}
+
+// POST /admin/ips/:ip/kick {"reason": "..."}
+func (a *API) kickIP(c *fiber.Ctx) error {
+	if err := a.rooms.KickIP(c.Params("ip"), reason(c, "kicked by admin")); err != nil {
+		return failErr(c, err)
+	}
+	return c.JSON(fiber.Map{"kicked": c.Params("ip")})
+}
+
+// GET /admin/bans
+func (a *API) bans(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"bans": a.rooms.Bans()})
+}
+
+// POST /admin/bans {"ip": "...", "reason": "..."}
+func (a *API) ban(c *fiber.Ctx) error {
+	var body struct {
+		IP     string `json:"ip"`
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.IP == "" {
+		return fail(c, fiber.StatusBadRequest, "body must be {\"ip\": string}")
+	}
+	if body.Reason == "" {
+		body.Reason = "banned by admin"
+	}
+	a.rooms.Ban(body.IP, body.Reason)
+	return c.JSON(fiber.Map{"banned": body.IP})
+}
+
+// DELETE /admin/bans/:ip
+func (a *API) unban(c *fiber.Ctx) error {
+	if !a.rooms.Unban(c.Params("ip")) {
+		return fail(c, fiber.StatusNotFound, "not banned")
+	}
+	return c.JSON(fiber.Map{"unbanned": c.Params("ip")})
+}
+
+// POST /admin/announce {"text": "...", "room": "lobby"}; without room it goes
+// to every room
+func (a *API) announce(c *fiber.Ctx) error {
+	var body struct {
+		Text string `json:"text"`
+		Room string `json:"room"`
+	}
+	if err := c.BodyParser(&body); err != nil || strings.TrimSpace(body.Text) == "" {
+		return fail(c, fiber.StatusBadRequest, "body must be {\"text\": string, \"room\"?: string}")
+	}
+	if err := a.rooms.Announce(body.Room, strings.TrimSpace(body.Text)); err != nil {
+		return failErr(c, err)
+	}
+	return c.JSON(fiber.Map{"announced": true})
+}
+
+// GET /admin/rooms
+func (a *API) roomList(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"rooms": a.rooms.RoomInfos()})
+}
+
+// POST /admin/rooms/:room/pause, /admin/rooms/:room/resume
+func (a *API) pause(paused bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		room := a.rooms.Get(c.Params("room"))
+		if room == nil {
+			return failErr(c, ws.ErrRoomNotFound)
+		}
+		room.SetPaused(paused)
+		return c.JSON(room.Info())
+	}
+}
+
+// GET /admin/rooms/:room/state: the room's whole GameState with its world
+// config and round
+func (a *API) state(c *fiber.Ctx) error {
+	room := a.rooms.Get(c.Params("room"))
+	if room == nil {
+		return failErr(c, ws.ErrRoomNotFound)
+	}
+	return c.JSON(room.Snapshot())
+}
+
+// POST /admin/rooms/:room/record: start recording the room into a replay
+func (a *API) record(c *fiber.Ctx) error {
+	room := a.rooms.Get(c.Params("room"))
+	if room == nil {
+		return failErr(c, ws.ErrRoomNotFound)
+	}
+	name, err := room.StartRecording()
+	if err != nil {
+		return failErr(c, err)
+	}
+	return c.JSON(fiber.Map{"recording": name})
+}
+
+// DELETE /admin/rooms/:room/record
+func (a *API) stopRecording(c *fiber.Ctx) error {
+	room := a.rooms.Get(c.Params("room"))
+	if room == nil {
+		return failErr(c, ws.ErrRoomNotFound)
+	}
+	name, err := room.StopRecording()
+	if err != nil {
+		return failErr(c, err)
+	}
+	return c.JSON(fiber.Map{"stopped": name})
+}
+
+// POST /admin/rooms/:room/bots {"target": 8}: fill the room with bots up to
+// target players (0 removes them)
+func (a *API) bots(c *fiber.Ctx) error {
+	var body struct {
+		Target *int `json:"target"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.Target == nil || *body.Target < 0 {
+		return fail(c, fiber.StatusBadRequest, "body must be {\"target\": number >= 0}")
+	}
+	room := a.rooms.Get(c.Params("room"))
+	if room == nil {
+		return failErr(c, ws.ErrRoomNotFound)
+	}
+	if err := room.SetBotTarget(*body.Target); err != nil {
+		return failErr(c, err)
+	}
+	return c.JSON(room.Info())
+}
+
+// GET /admin/replays
+func (a *API) replays(c *fiber.Ctx) error {
+	replays, err := a.rooms.Replays()
+	if err != nil {
+		return failErr(c, err)
+	}
+	return c.JSON(fiber.Map{"replays": replays})
+}
+
+// GET /admin/replays/:name: the replay file itself, e.g. to attach to a bug
+// report
+func (a *API) downloadReplay(c *fiber.Ctx) error {
+	path, err := a.rooms.ReplayPath(c.Params("name"))
+	if err != nil {
+		return failErr(c, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return failErr(c, ws.ErrReplayNotFound)
+	}
+	return c.Download(path)
+}
+
+// GET /admin/drain
+func (a *API) drainStatus(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"draining": a.rooms.Draining()})
+}
+
+// POST /admin/drain {"draining": true}: refuse new logins (e.g. before a
+// deploy) while current players finish; false opens them again
+func (a *API) drain(c *fiber.Ctx) error {
+	var body struct {
+		Draining *bool `json:"draining"`
+	}
+	if err := c.BodyParser(&body); err != nil || body.Draining == nil {
+		return fail(c, fiber.StatusBadRequest, "body must be {\"draining\": bool}")
+	}
+	a.rooms.SetDraining(*body.Draining)
+	return c.JSON(fiber.Map{"draining": *body.Draining})
+}
@@ -0,0 +1,156 @@
+// Package chat checks, filters and remembers chat messages. Delivering them
+// to connections is up to the caller; the service only decides whether a
+// message may be sent and what it says.
+package chat
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// Config is the chat limits, loaded with the rest of the server config
+type Config struct {
+	MaxLength   int      `json:"maxLength" yaml:"maxLength"`     // 메시지 최대 글자 수
+	HistorySize int      `json:"historySize" yaml:"historySize"` // 채널마다 보관할 최근 메시지 수
+	Rate        float64  `json:"rate" yaml:"rate"`               // 플레이어당 초당 허용 메시지 수
+	Burst       int      `json:"burst" yaml:"burst"`             // 연속으로 보낼 수 있는 메시지 수
+	BannedWords []string `json:"bannedWords" yaml:"bannedWords"` // 금지어 (대소문자 무시)
+	DropBanned  bool     `json:"dropBanned" yaml:"dropBanned"`   // true면 금지어가 든 메시지를 버림, false면 *로 가림
+}
+
+// DefaultConfig returns the limits used when nothing is configured
+func DefaultConfig() Config {
+	return Config{
+		MaxLength:   200,
+		HistorySize: 50,
+		Rate:        1,
+		Burst:       5,
+	}
+}
+
+// 메시지를 거부한 이유
+var (
+	ErrEmpty       = errors.New("chat: empty message")
+	ErrTooLong     = errors.New("chat: message too long")
+	ErrRateLimited = errors.New("chat: sending too fast")
+	ErrFiltered    = errors.New("chat: message blocked by filter")
+	ErrBadChannel  = errors.New("chat: unknown channel")
+)
+
+// Service accepts chat messages and keeps the recent ones
+type Service struct {
+	cfg     Config
+	filters []Filter
+
+	mu     sync.Mutex
+	nextID uint64
+	limits *limiter
+	global *history
+	rooms  map[string]*history
+}
+
+// New creates a chat service. Banned words in cfg become the first filter;
+// filters are then applied in order.
+func New(cfg Config, filters ...Filter) *Service {
+	if len(cfg.BannedWords) > 0 {
+		filters = append([]Filter{NewWordFilter(cfg.BannedWords, cfg.DropBanned)}, filters...)
+	}
+	return &Service{
+		cfg:     cfg,
+		filters: filters,
+		limits:  newLimiter(cfg.Rate, cfg.Burst),
+		global:  newHistory(cfg.HistorySize),
+		rooms:   make(map[string]*history),
+	}
+}
+
+// Accept checks a message from player, runs it through the filters and, for
+// global and room messages, adds it to the history. room is the sender's
+// room; to is the receiving player for direct messages. The returned message
+// is what should be delivered.
+func (s *Service) Accept(from *models.Player, room string, channel models.ChatChannel, to, text string) (models.ChatMessage, error) {
+	text = strings.TrimSpace(text)
+	switch {
+	case channel != models.ChatGlobal && channel != models.ChatRoom && channel != models.ChatDirect:
+		return models.ChatMessage{}, ErrBadChannel
+	case text == "":
+		return models.ChatMessage{}, ErrEmpty
+	case s.cfg.MaxLength > 0 && utf8.RuneCountInString(text) > s.cfg.MaxLength:
+		return models.ChatMessage{}, ErrTooLong
+	}
+
+	// 걸러질 메시지도 보낸 횟수에 포함
+	now := time.Now()
+	s.mu.Lock()
+	allowed := s.limits.allow(from.ID, now)
+	s.mu.Unlock()
+	if !allowed {
+		return models.ChatMessage{}, ErrRateLimited
+	}
+
+	for _, f := range s.filters {
+		var ok bool
+		if text, ok = f.Filter(from.ID, text); !ok {
+			return models.ChatMessage{}, ErrFiltered
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	msg := models.ChatMessage{
+		ID:       s.nextID,
+		Channel:  channel,
+		From:     from.ID,
+		FromName: from.Name,
+		Text:     text,
+		SentAt:   now.UnixMilli(),
+	}
+	switch channel {
+	case models.ChatGlobal:
+		s.global.add(msg)
+	case models.ChatRoom:
+		msg.Room = room
+		h, ok := s.rooms[room]
+		if !ok {
+			h = newHistory(s.cfg.HistorySize)
+			s.rooms[room] = h
+		}
+		h.add(msg)
+	case models.ChatDirect:
+		msg.To = to
+	}
+	return msg, nil
+}
+
+// History returns the recent global messages and those of room
+func (s *Service) History(room string) models.ChatHistory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hist := models.ChatHistory{Global: s.global.messages()}
+	if h, ok := s.rooms[room]; ok {
+		hist.Room = h.messages()
+	} else {
+		hist.Room = []models.ChatMessage{}
+	}
+	return hist
+}
+
+// Forget drops the rate limit state of a player that left
+func (s *Service) Forget(playerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits.forget(playerID)
+}
+
+// CloseRoom drops the history of a room that was torn down
+func (s *Service) CloseRoom(room string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rooms, room)
+}
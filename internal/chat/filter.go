@@ -0,0 +1,79 @@
+package chat
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Filter inspects a chat message before it is delivered. It returns the
+// text to deliver, possibly changed (e.g. masked), or false to drop the
+// message.
+type Filter interface {
+	Filter(from, text string) (string, bool)
+}
+
+// FilterFunc adapts a function to Filter
+type FilterFunc func(from, text string) (string, bool)
+
+// Filter implements Filter
+func (f FilterFunc) Filter(from, text string) (string, bool) {
+	return f(from, text)
+}
+
+// WordFilter masks banned words with '*', one per character, or drops
+// messages containing them. Matching is case-insensitive and also finds
+// words inside longer words.
+type WordFilter struct {
+	words [][]rune // 소문자
+	drop  bool
+}
+
+// NewWordFilter creates a filter for words; with drop set, a message
+// containing any of them is dropped instead of masked
+func NewWordFilter(words []string, drop bool) *WordFilter {
+	f := &WordFilter{drop: drop}
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			f.words = append(f.words, toLower([]rune(w)))
+		}
+	}
+	return f
+}
+
+// Filter implements Filter
+func (f *WordFilter) Filter(from, text string) (string, bool) {
+	runes := []rune(text)
+	lower := toLower(slices.Clone(runes))
+
+	found := false
+	for _, w := range f.words {
+		for i := 0; i+len(w) <= len(lower); {
+			if !slices.Equal(lower[i:i+len(w)], w) {
+				i++
+				continue
+			}
+			found = true
+			for j := i; j < i+len(w); j++ {
+				runes[j] = '*'
+			}
+			i += len(w)
+		}
+	}
+	if !found {
+		return text, true
+	}
+	if f.drop {
+		return "", false
+	}
+	return string(runes), true
+}
+
+// toLower lowercases runes in place, one rune for one (unlike strings.ToLower,
+// positions stay the same)
+func toLower(runes []rune) []rune {
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+func TestWordFilter(t *testing.T) {
+	words := []string{"darn", " Heck ", "", "ß"}
+	tests := []struct {
+		name   string
+		drop   bool
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{"clean", false, "hello there", "hello there", true},
+		{"masked", false, "oh darn it", "oh **** it", true},
+		{"case-insensitive", false, "DARN and HeCk", "**** and ****", true},
+		{"inside a longer word", false, "darned", "****ed", true},
+		{"repeated", false, "darndarn darn", "******** ****", true},
+		{"trimmed word list", false, "what the heck", "what the ****", true},
+		{"multibyte keeps positions", false, "안녕 Darn 👋 ß", "안녕 **** 👋 *", true},
+		{"dropped", true, "oh darn it", "", false},
+		{"clean with drop", true, "hello", "hello", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewWordFilter(words, tt.drop).Filter("p1", tt.text)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Filter(%q) = %q, %v, want %q, %v", tt.text, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAcceptRunsFilters(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.BannedWords = []string{"darn"}
+	shout := FilterFunc(func(from, text string) (string, bool) { return text + "!", true })
+	s := New(cfg, shout)
+
+	from := &models.Player{ID: "p1", Name: "ada"}
+	msg, err := s.Accept(from, "lobby", models.ChatRoom, "", "  darn  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 금지어 필터가 먼저, 그다음 넘겨준 필터 순서
+	if msg.Text != "****!" {
+		t.Errorf("text %q, want %q", msg.Text, "****!")
+	}
+
+	cfg.DropBanned = true
+	if _, err := New(cfg).Accept(from, "lobby", models.ChatRoom, "", "darn"); err != ErrFiltered {
+		t.Errorf("dropped message: %v, want %v", err, ErrFiltered)
+	}
+}
@@ -0,0 +1,35 @@
+package chat
+
+import "github.com/sangjinsu/websocket-multiplayer/internal/models"
+
+// history is a ring buffer of the most recent messages of one channel.
+// The Service lock guards it.
+type history struct {
+	buf  []models.ChatMessage
+	next int // 다음에 쓸 위치
+	full bool
+}
+
+// newHistory keeps up to size messages; 0 keeps none
+func newHistory(size int) *history {
+	return &history{buf: make([]models.ChatMessage, max(size, 0))}
+}
+
+func (h *history) add(msg models.ChatMessage) {
+	if len(h.buf) == 0 {
+		return
+	}
+	h.buf[h.next] = msg
+	h.next = (h.next + 1) % len(h.buf)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// messages returns the kept messages, oldest first
+func (h *history) messages() []models.ChatMessage {
+	if !h.full {
+		return append([]models.ChatMessage{}, h.buf[:h.next]...)
+	}
+	return append(append([]models.ChatMessage{}, h.buf[h.next:]...), h.buf[:h.next]...)
+}
@@ -0,0 +1,49 @@
+package chat
+
+import "time"
+
+// limiter is a token bucket per player: each player may send burst messages
+// at once, refilled at rate per second. The Service lock guards it.
+type limiter struct {
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter creates a limiter; a rate of 0 or less turns limiting off
+func newLimiter(rate float64, burst int) *limiter {
+	return &limiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow takes a token for player and reports whether one was available
+func (l *limiter) allow(player string, now time.Time) bool {
+	if l.rate <= 0 {
+		return true
+	}
+	b, ok := l.buckets[player]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[player] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// forget drops a player's bucket
+func (l *limiter) forget(player string) {
+	delete(l.buckets, player)
+}
@@ -0,0 +1,70 @@
+package chat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterRefill(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	type send struct {
+		after time.Duration // start부터
+		want  bool
+	}
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		sends []send
+	}{
+		{"burst then refused", 1, 3, []send{{0, true}, {0, true}, {0, true}, {0, false}}},
+		{"one token per second", 1, 2, []send{
+			{0, true}, {0, true}, {0, false},
+			{999 * time.Millisecond, false},
+			{time.Second, true},
+			{time.Second, false},
+		}},
+		{"partial tokens add up", 2, 1, []send{
+			{0, true},
+			{200 * time.Millisecond, false},
+			{400 * time.Millisecond, false},
+			{500 * time.Millisecond, true},
+		}},
+		{"refill capped at burst", 10, 2, []send{
+			{0, true}, {0, true},
+			{time.Minute, true}, {time.Minute, true}, {time.Minute, false},
+		}},
+		{"refused sends don't cost a token", 1, 1, []send{
+			{0, true},
+			{500 * time.Millisecond, false},
+			{time.Second, true},
+		}},
+		{"burst below one acts as one", 1, 0, []send{{0, true}, {0, false}}},
+		{"rate zero turns limiting off", 0, 1, []send{{0, true}, {0, true}, {0, true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLimiter(tt.rate, tt.burst)
+			for i, s := range tt.sends {
+				if got := l.allow("p1", start.Add(s.after)); got != s.want {
+					t.Fatalf("send %d at +%v: allowed = %v, want %v", i, s.after, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterPerPlayer(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := newLimiter(1, 1)
+	if !l.allow("p1", now) || l.allow("p1", now) {
+		t.Fatal("p1 should get exactly one message")
+	}
+	if !l.allow("p2", now) {
+		t.Error("p2 was limited by p1's messages")
+	}
+	l.forget("p1")
+	if !l.allow("p1", now) {
+		t.Error("p1 still limited after forget")
+	}
+}
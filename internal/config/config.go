@@ -0,0 +1,254 @@
+// Package config loads the server configuration: built-in defaults, then an
+// optional YAML or JSON file, then environment variables on top.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/chat"
+	"github.com/sangjinsu/websocket-multiplayer/internal/game"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+	ws "github.com/sangjinsu/websocket-multiplayer/internal/websocket"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is everything the server reads at startup
+type Config struct {
+	Port     string `json:"port" yaml:"port"`
+	SimRate  int    `json:"simRate" yaml:"simRate"`   // 초당 물리 step 수
+	SendRate int    `json:"sendRate" yaml:"sendRate"` // 초당 game_state 전송 수
+	MapFile  string `json:"mapFile" yaml:"mapFile"`   // 맵 파일 (비우면 world.map 사용)
+
+	SendQueueSize int    `json:"sendQueueSize" yaml:"sendQueueSize"` // 연결마다 보내기를 기다릴 수 있는 프레임 수
+	QueuePolicy   string `json:"queuePolicy" yaml:"queuePolicy"`     // 큐가 가득 차면: "drop_stale" 또는 "disconnect"
+
+	SpectatorDelay float64 `json:"spectatorDelay" yaml:"spectatorDelay"` // 관전 지연 (초, 0 = 실시간)
+	ReconnectGrace float64 `json:"reconnectGrace" yaml:"reconnectGrace"` // 끊긴 플레이어가 재접속을 기다리는 시간 (초, 0 = 바로 제거)
+	AdminToken     string  `json:"adminToken" yaml:"adminToken"`         // /admin API 토큰 (비우면 API 비활성)
+
+	ShutdownCountdown float64 `json:"shutdownCountdown" yaml:"shutdownCountdown"` // SIGTERM 후 연결을 닫기까지 (초)
+	SnapshotFile      string  `json:"snapshotFile" yaml:"snapshotFile"`           // 종료 시 상태를 저장할 파일 (비우면 저장 안 함)
+
+	ReplayDir     string `json:"replayDir" yaml:"replayDir"`         // 리플레이 파일 디렉터리 (비우면 녹화/재생 비활성)
+	RecordReplays bool   `json:"recordReplays" yaml:"recordReplays"` // 모든 방을 생성부터 닫힐 때까지 녹화
+
+	World    models.WorldConfig `json:"world" yaml:"world"`
+	Chat     chat.Config        `json:"chat" yaml:"chat"`
+	Interest InterestConfig     `json:"interest" yaml:"interest"`
+}
+
+// InterestConfig is the area of interest around each player. All zero sends
+// every client the whole room.
+type InterestConfig struct {
+	Radius float64 `json:"radius" yaml:"radius"` // 원형 시야 반지름 (px), 0이면 width/height 사각형 사용
+	Width  float64 `json:"width" yaml:"width"`   // 사각형 시야 너비 (px)
+	Height float64 `json:"height" yaml:"height"` // 사각형 시야 높이 (px)
+}
+
+// Default returns the configuration used when nothing is set
+func Default() Config {
+	return Config{
+		Port:     "3000",
+		SimRate:  game.TickRate,
+		SendRate: game.TickRate,
+		World:    models.DefaultWorldConfig(),
+		Chat:     chat.DefaultConfig(),
+
+		SendQueueSize:  ws.DefaultOptions().SendQueueSize,
+		QueuePolicy:    "drop_stale",
+		ReconnectGrace: ws.DefaultOptions().ReconnectGrace.Seconds(),
+
+		ShutdownCountdown: 5,
+	}
+}
+
+// Load builds the configuration from the file at path (YAML for .yaml/.yml,
+// JSON otherwise; an empty path or a file that doesn't exist skips it) and
+// the environment. Settings missing from the file keep their defaults. If a map file is set,
+// it replaces world.map, and its width and height, when given, replace the
+// arena size.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		err := readFile(path, &cfg)
+		if errors.Is(err, fs.ErrNotExist) {
+			// 파일이 없으면 기본값 + 환경 변수로 시작
+			log.Printf("Config file %s not found, using the defaults", path)
+		} else if err != nil {
+			return Config{}, err
+		}
+	}
+	if err := applyEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+	if cfg.MapFile != "" {
+		m, err := LoadMap(cfg.MapFile)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.World.Map = m
+	}
+	if m := cfg.World.Map; m.Width > 0 && m.Height > 0 {
+		cfg.World.Width, cfg.World.Height = m.Width, m.Height
+	}
+	if err := cfg.World.Validate(); err != nil {
+		return Config{}, fmt.Errorf("world config: %w", err)
+	}
+	if _, err := game.NewMode(cfg.World.Round.Mode); err != nil {
+		return Config{}, fmt.Errorf("world config: %w", err)
+	}
+	for _, name := range cfg.World.Bots.Behaviors {
+		if _, err := game.NewBehavior(name, cfg.World.Bots); err != nil {
+			return Config{}, fmt.Errorf("world config: bots: %w", err)
+		}
+	}
+	if cfg.SendQueueSize <= 0 {
+		return Config{}, fmt.Errorf("sendQueueSize must be positive")
+	}
+	if _, err := ws.ParseQueuePolicy(cfg.QueuePolicy); err != nil {
+		return Config{}, fmt.Errorf("queuePolicy: %w", err)
+	}
+	if i := cfg.Interest; i.Radius < 0 || i.Width < 0 || i.Height < 0 {
+		return Config{}, fmt.Errorf("interest config: radius, width and height must not be negative")
+	}
+	if cfg.SpectatorDelay < 0 {
+		return Config{}, fmt.Errorf("spectatorDelay must not be negative")
+	}
+	if cfg.ReconnectGrace < 0 {
+		return Config{}, fmt.Errorf("reconnectGrace must not be negative")
+	}
+	if cfg.ShutdownCountdown < 0 {
+		return Config{}, fmt.Errorf("shutdownCountdown must not be negative")
+	}
+	if cfg.RecordReplays && cfg.ReplayDir == "" {
+		return Config{}, fmt.Errorf("recordReplays needs replayDir")
+	}
+	if cfg.Chat.MaxLength < 0 || cfg.Chat.HistorySize < 0 || cfg.Chat.Burst < 0 {
+		return Config{}, fmt.Errorf("chat config: maxLength, historySize and burst must not be negative")
+	}
+	if cfg.SimRate <= 0 || cfg.SendRate <= 0 || cfg.SendRate > cfg.SimRate {
+		return Config{}, fmt.Errorf("simRate (%d) and sendRate (%d) must be positive with sendRate <= simRate", cfg.SimRate, cfg.SendRate)
+	}
+	return cfg, nil
+}
+
+// LoadMap reads a map file (YAML for .yaml/.yml, JSON otherwise). The map is
+// validated together with the rest of the world config.
+func LoadMap(path string) (models.Map, error) {
+	var m models.Map
+	if err := decodeFile("map", path, &m); err != nil {
+		return models.Map{}, err
+	}
+	return m, nil
+}
+
+func readFile(path string, cfg *Config) error {
+	return decodeFile("config", path, cfg)
+}
+
+// decodeFile decodes a YAML or JSON file into v, rejecting unknown fields
+func decodeFile(kind, path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", kind, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(v)
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(v)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing %s %s: %w", kind, path, err)
+	}
+	return nil
+}
+
+// 환경 변수 -> 설정 (파일 값보다 우선)
+var envVars = []struct {
+	name  string
+	apply func(cfg *Config, value string) error
+}{
+	{"PORT", func(cfg *Config, v string) error { cfg.Port = v; return nil }},
+	{"MAP_FILE", func(cfg *Config, v string) error { cfg.MapFile = v; return nil }},
+	{"SIM_RATE", intVar(func(cfg *Config) *int { return &cfg.SimRate })},
+	{"SEND_RATE", intVar(func(cfg *Config) *int { return &cfg.SendRate })},
+	{"SEND_QUEUE_SIZE", intVar(func(cfg *Config) *int { return &cfg.SendQueueSize })},
+	{"QUEUE_POLICY", func(cfg *Config, v string) error { cfg.QueuePolicy = v; return nil }},
+	{"WORLD_WIDTH", floatVar(func(cfg *Config) *float64 { return &cfg.World.Width })},
+	{"WORLD_HEIGHT", floatVar(func(cfg *Config) *float64 { return &cfg.World.Height })},
+	{"PLAYER_RADIUS", floatVar(func(cfg *Config) *float64 { return &cfg.World.PlayerRadius })},
+	{"MAX_PLAYERS", intVar(func(cfg *Config) *int { return &cfg.World.MaxPlayers })},
+	{"INTEREST_RADIUS", floatVar(func(cfg *Config) *float64 { return &cfg.Interest.Radius })},
+	{"INTEREST_WIDTH", floatVar(func(cfg *Config) *float64 { return &cfg.Interest.Width })},
+	{"INTEREST_HEIGHT", floatVar(func(cfg *Config) *float64 { return &cfg.Interest.Height })},
+	{"SPECTATOR_DELAY", floatVar(func(cfg *Config) *float64 { return &cfg.SpectatorDelay })},
+	{"RECONNECT_GRACE", floatVar(func(cfg *Config) *float64 { return &cfg.ReconnectGrace })},
+	{"BOTS", intVar(func(cfg *Config) *int { return &cfg.World.Bots.Target })},
+	{"GAME_MODE", func(cfg *Config, v string) error { cfg.World.Round.Mode = v; return nil }},
+	{"ADMIN_TOKEN", func(cfg *Config, v string) error { cfg.AdminToken = v; return nil }},
+	{"SHUTDOWN_COUNTDOWN", floatVar(func(cfg *Config) *float64 { return &cfg.ShutdownCountdown })},
+	{"SNAPSHOT_FILE", func(cfg *Config, v string) error { cfg.SnapshotFile = v; return nil }},
+	{"REPLAY_DIR", func(cfg *Config, v string) error { cfg.ReplayDir = v; return nil }},
+	{"RECORD_REPLAYS", boolVar(func(cfg *Config) *bool { return &cfg.RecordReplays })},
+}
+
+func applyEnv(cfg *Config) error {
+	for _, env := range envVars {
+		value, ok := os.LookupEnv(env.name)
+		if !ok || value == "" {
+			continue
+		}
+		if err := env.apply(cfg, value); err != nil {
+			return fmt.Errorf("environment variable %s: %w", env.name, err)
+		}
+	}
+	return nil
+}
+
+func intVar(field func(*Config) *int) func(*Config, string) error {
+	return func(cfg *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = n
+		return nil
+	}
+}
+
+func floatVar(field func(*Config) *float64) func(*Config, string) error {
+	return func(cfg *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = f
+		return nil
+	}
+}
+
+func boolVar(field func(*Config) *bool) func(*Config, string) error {
+	return func(cfg *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		*field(cfg) = b
+		return nil
+	}
+}
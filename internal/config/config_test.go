@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// clearEnv hides the server's environment variables from the test (empty
+// values are ignored)
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range envVars {
+		t.Setenv(env.name, "")
+	}
+}
+
+// writeConfig writes a config file named name and returns its path
+func writeConfig(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": "port: \"8080\"\nsendRate: 20\nworld:\n  width: 1000\n  round:\n    mode: tag\nchat:\n  maxLength: 50\n",
+		"config.yml":  "port: \"8080\"\nsendRate: 20\nworld:\n  width: 1000\n  round:\n    mode: tag\nchat:\n  maxLength: 50\n",
+		"config.json": `{"port": "8080", "sendRate": 20, "world": {"width": 1000, "round": {"mode": "tag"}}, "chat": {"maxLength": 50}}`,
+	}
+	for name, data := range files {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			cfg, err := Load(writeConfig(t, name, data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := Default()
+			want.Port = "8080"
+			want.SendRate = 20
+			want.World.Width = 1000
+			want.World.Round.Mode = "tag"
+			want.Chat.MaxLength = 50
+			if !reflect.DeepEqual(cfg, want) {
+				t.Errorf("loaded\n%+v\nwant the defaults with the file's settings\n%+v", cfg, want)
+			}
+		})
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("PORT", "9090")
+	t.Setenv("WORLD_WIDTH", "1200")
+	t.Setenv("BOTS", "4")
+	t.Setenv("RECORD_REPLAYS", "true")
+	t.Setenv("REPLAY_DIR", "replays")
+	path := writeConfig(t, "config.yaml", "port: \"8080\"\nworld:\n  width: 1000\n  height: 700\n")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != "9090" || cfg.World.Width != 1200 || cfg.World.Bots.Target != 4 || !cfg.RecordReplays || cfg.ReplayDir != "replays" {
+		t.Errorf("port %s, width %g, bots %d, record %v in %q, want the environment's", cfg.Port, cfg.World.Width, cfg.World.Bots.Target, cfg.RecordReplays, cfg.ReplayDir)
+	}
+	// 환경 변수가 없는 값은 파일 값
+	if cfg.World.Height != 700 {
+		t.Errorf("height %g, want the file's 700", cfg.World.Height)
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want string // 오류 메시지에 들어 있어야 하는 부분
+	}{
+		{name: "unknown field", file: `{"prot": "8080"}`, want: "prot"},
+		{name: "malformed", file: `{"port": `, want: "parsing config"},
+		{name: "send faster than sim", file: `{"simRate": 30, "sendRate": 60}`, want: "sendRate"},
+		{name: "queue size", file: `{"sendQueueSize": 0}`, want: "sendQueueSize"},
+		{name: "queue policy", file: `{"queuePolicy": "block"}`, want: "queuePolicy"},
+		{name: "negative grace", file: `{"reconnectGrace": -1}`, want: "reconnectGrace"},
+		{name: "negative interest", file: `{"interest": {"radius": -5}}`, want: "interest"},
+		{name: "replays without dir", file: `{"recordReplays": true}`, want: "replayDir"},
+		{name: "game mode", file: `{"world": {"round": {"mode": "soccer"}}}`, want: "world config"},
+		{name: "bot behavior", file: `{"world": {"bots": {"behaviors": ["dance"]}}}`, want: "bots"},
+		{name: "world size", file: `{"world": {"width": -1}}`, want: "world config"},
+		{name: "env number", env: map[string]string{"SIM_RATE": "fast"}, want: "SIM_RATE"},
+		{name: "env bool", env: map[string]string{"RECORD_REPLAYS": "sometimes"}, want: "RECORD_REPLAYS"},
+		{name: "env over valid file", file: `{"simRate": 60}`, env: map[string]string{"SEND_RATE": "120"}, want: "sendRate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			path := ""
+			if tt.file != "" {
+				path = writeConfig(t, "config.json", tt.file)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	for name, path := range map[string]string{
+		"no path":      "",
+		"missing file": filepath.Join(t.TempDir(), "missing.yaml"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg, Default()) {
+				t.Errorf("loaded\n%+v\nwant the defaults\n%+v", cfg, Default())
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package game
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// BotBehavior decides how a bot plays. Every think it looks at the world and
+// picks the bot's next input, which goes through ApplyInput or
+// ApplyVelocityInput like a human's. Each bot gets its own instance, so a
+// behavior can keep state between thinks.
+type BotBehavior interface {
+	// Name returns the behavior's name in BotConfig.Behaviors
+	Name() string
+
+	// Think returns the bot's next input, or false to leave it coasting
+	Think(view BotView) (models.PlayerInput, bool)
+}
+
+// BotView is the world as a bot sees it when it thinks
+type BotView struct {
+	Self    models.PlayerState
+	Others  []models.PlayerState // 자기 자신을 뺀 모든 플레이어 (ID 순)
+	World   models.WorldConfig
+	Elapsed time.Duration // 지난 think 이후 흐른 시간
+	Rand    *rand.Rand
+}
+
+// nearest returns the closest other player that isn't away
+func (v BotView) nearest() (models.PlayerState, float64, bool) {
+	var best models.PlayerState
+	bestDist := math.Inf(1)
+	for _, p := range v.Others {
+		if p.Away {
+			continue
+		}
+		if d := math.Hypot(p.X-v.Self.X, p.Y-v.Self.Y); d < bestDist {
+			best, bestDist = p, d
+		}
+	}
+	return best, bestDist, !math.IsInf(bestDist, 1)
+}
+
+// randomPoint returns a point a player fits at, anywhere in the arena
+func (v BotView) randomPoint() models.Point {
+	r := v.World.PlayerRadius
+	return models.Point{
+		X: r + v.Rand.Float64()*(v.World.Width-2*r),
+		Y: r + v.Rand.Float64()*(v.World.Height-2*r),
+	}
+}
+
+// 봇이 내는 속도 (WorldConfig.MaxSpeed 대비)
+const botSpeed = 0.6
+
+// steer returns a velocity input towards (x, y) at speed px/s
+func steer(self models.PlayerState, x, y, speed float64) models.PlayerInput {
+	dx, dy := x-self.X, y-self.Y
+	d := math.Hypot(dx, dy)
+	if d == 0 {
+		return models.PlayerInput{HasVelocity: true}
+	}
+	return models.PlayerInput{HasVelocity: true, Vx: dx / d * speed, Vy: dy / d * speed}
+}
+
+// 설정 이름 -> 봇 행동 생성자
+var behaviors = map[string]func(cfg models.BotConfig) BotBehavior{
+	"wander": func(models.BotConfig) BotBehavior { return &wander{} },
+	"chase":  func(models.BotConfig) BotBehavior { return &chase{} },
+	"flee":   func(models.BotConfig) BotBehavior { return &flee{} },
+	"path":   func(cfg models.BotConfig) BotBehavior { return &path{points: cfg.Path} },
+}
+
+// BehaviorNames returns the names of every bot behavior, sorted
+func BehaviorNames() []string {
+	names := make([]string, 0, len(behaviors))
+	for name := range behaviors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// NewBehavior creates the bot behavior with the given name for bots
+// configured by cfg
+func NewBehavior(name string, cfg models.BotConfig) (BotBehavior, error) {
+	newBehavior, ok := behaviors[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown bot behavior %q", name)
+	}
+	return newBehavior(cfg), nil
+}
+
+// wander walks to random points with WASD keys, like a human idling around
+type wander struct {
+	target models.Point
+	left   time.Duration // 새 목표를 고를 때까지
+}
+
+func (b *wander) Name() string { return "wander" }
+
+func (b *wander) Think(v BotView) (models.PlayerInput, bool) {
+	dx, dy := b.target.X-v.Self.X, b.target.Y-v.Self.Y
+	b.left -= v.Elapsed
+	if b.left <= 0 || math.Hypot(dx, dy) < v.World.MinDistance() {
+		// 도착했거나 (장애물에 막혀) 너무 오래 걸리면 다른 곳으로
+		b.target = v.randomPoint()
+		b.left = 3*time.Second + time.Duration(v.Rand.Int63n(int64(3*time.Second)))
+		dx, dy = b.target.X-v.Self.X, b.target.Y-v.Self.Y
+	}
+
+	// 더 먼 축으로 키를 누르되, 이미 그 방향으로 충분히 빠르면 쉼
+	key, vel, dist := "d", v.Self.Vx, dx
+	if math.Abs(dy) > math.Abs(dx) {
+		key, vel, dist = "s", v.Self.Vy, dy
+	}
+	if dist < 0 {
+		key = map[string]string{"d": "a", "s": "w"}[key]
+		vel, dist = -vel, -dist
+	}
+	if vel >= v.World.KeySpeed {
+		return models.PlayerInput{}, false
+	}
+	return models.PlayerInput{Key: key}, true
+}
+
+// chase runs at the nearest player; alone, it wanders
+type chase struct {
+	idle wander
+}
+
+func (b *chase) Name() string { return "chase" }
+
+func (b *chase) Think(v BotView) (models.PlayerInput, bool) {
+	target, _, ok := v.nearest()
+	if !ok {
+		return b.idle.Think(v)
+	}
+	return steer(v.Self, target.X, target.Y, v.World.MaxSpeed*botSpeed), true
+}
+
+// 이 거리(플레이어 반지름 배수) 안에 누가 오면 flee 봇이 도망침
+const fleeRadius = 8
+
+// flee runs away from the nearest player that comes close, and wanders
+// otherwise
+type flee struct {
+	idle wander
+}
+
+func (b *flee) Name() string { return "flee" }
+
+func (b *flee) Think(v BotView) (models.PlayerInput, bool) {
+	threat, dist, ok := v.nearest()
+	if !ok || dist > fleeRadius*v.World.PlayerRadius {
+		return b.idle.Think(v)
+	}
+	// 반대 방향 지점을 아레나 안으로 당겨서 벽에 붙으면 벽을 따라 미끄러지게 함
+	x := v.Self.X + (v.Self.X - threat.X)
+	y := v.Self.Y + (v.Self.Y - threat.Y)
+	if dist == 0 {
+		x, y = v.randomPoint().X, v.randomPoint().Y
+	}
+	x, y = v.World.Clamp(x, y)
+	return steer(v.Self, x, y, v.World.MaxSpeed*botSpeed), true
+}
+
+// path walks a loop of waypoints (BotConfig.Path, or a lap just inside the
+// arena), starting at the nearest one
+type path struct {
+	points []models.Point
+	next   int
+	begun  bool
+}
+
+func (b *path) Name() string { return "path" }
+
+func (b *path) Think(v BotView) (models.PlayerInput, bool) {
+	if len(b.points) == 0 {
+		// 기본 경로: 아레나 가장자리에서 1/5 들어온 사각형
+		w, h := v.World.Width, v.World.Height
+		b.points = []models.Point{{X: w * 0.2, Y: h * 0.2}, {X: w * 0.8, Y: h * 0.2}, {X: w * 0.8, Y: h * 0.8}, {X: w * 0.2, Y: h * 0.8}}
+	}
+	if !b.begun {
+		b.begun = true
+		best := math.Inf(1)
+		for i, p := range b.points {
+			if d := math.Hypot(p.X-v.Self.X, p.Y-v.Self.Y); d < best {
+				b.next, best = i, d
+			}
+		}
+	}
+	p := b.points[b.next]
+	if math.Hypot(p.X-v.Self.X, p.Y-v.Self.Y) < v.World.MinDistance() {
+		b.next = (b.next + 1) % len(b.points)
+		p = b.points[b.next]
+	}
+	return steer(v.Self, p.X, p.Y, v.World.MaxSpeed*botSpeed), true
+}
@@ -0,0 +1,210 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// Bots keeps a game's server-side bots: players with no connection that
+// play through ApplyInput and ApplyVelocityInput like everyone else. It adds
+// bots while there are fewer than Target players and removes them as humans
+// join, so they show up in game_state like any other player.
+type Bots struct {
+	game *Game
+	rng  *rand.Rand
+
+	mu   sync.Mutex
+	cfg  models.BotConfig
+	bots map[string]*bot // 플레이어 ID -> 봇
+	next int             // 다음 봇에게 줄 행동 (cfg.Behaviors 순서)
+	seq  int             // 봇 이름 번호
+	acc  time.Duration   // 지난 think 이후 흐른 시간
+}
+
+// bot is one bot and its behavior
+type bot struct {
+	id       string
+	behavior BotBehavior
+	seq      uint32 // 마지막으로 보낸 입력 번호
+}
+
+// newBots creates the bot controller of g with no bots in it yet
+func newBots(g *Game, cfg models.BotConfig) *Bots {
+	if cfg.ThinkRate <= 0 {
+		cfg.ThinkRate = models.DefaultBotConfig().ThinkRate
+	}
+	return &Bots{
+		game: g,
+		rng:  rand.New(rand.NewSource(time.Now().UnixNano())),
+		cfg:  cfg,
+		bots: make(map[string]*bot),
+	}
+}
+
+// Bots returns the game's bot controller
+func (g *Game) Bots() *Bots {
+	return g.bots
+}
+
+// Target returns the number of players the bots fill the world up to
+func (b *Bots) Target() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.cfg.Target
+}
+
+// SetTarget changes the number of players the bots fill the world up to;
+// bots join or leave on the next Update
+func (b *Bots) SetTarget(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.Target = max(0, n)
+}
+
+// Count returns how many bots are in the world
+func (b *Bots) Count() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.bots)
+}
+
+// Update advances the bots by dt: once every think interval it adds or
+// removes bots to match the target and has each bot pick its next input.
+// It returns the bots that joined and the IDs of the ones that left, for the
+// caller to announce.
+func (b *Bots) Update(dt time.Duration) (joined []models.PlayerState, left []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.acc += dt
+	interval := b.cfg.ThinkInterval()
+	if b.acc < interval {
+		return nil, nil
+	}
+	elapsed := b.acc
+	b.acc = 0
+
+	players := b.game.playerStates()
+	left = b.prune(players)
+	if len(b.bots) == 0 && b.cfg.Target == 0 {
+		return nil, left
+	}
+
+	// 사람 수를 빼고 남은 자리만큼 봇 유지
+	humans := len(players) - len(b.bots)
+	want := max(0, b.cfg.Target-humans)
+	for len(b.bots) > want {
+		id := b.newest(players)
+		b.game.RemovePlayer(id)
+		delete(b.bots, id)
+		left = append(left, id)
+	}
+	for len(b.bots) < want {
+		state, err := b.add()
+		if errors.Is(err, ErrGameFull) {
+			break
+		}
+		if err != nil {
+			log.Printf("Error adding bot: %v", err)
+			break
+		}
+		joined = append(joined, state)
+	}
+	if len(joined) > 0 || len(left) > 0 {
+		players = b.game.playerStates()
+	}
+
+	// 봇마다 다음 입력 결정 (ID 순이라 결과가 봇 map 순서에 좌우되지 않음)
+	for i, self := range players {
+		bt, ok := b.bots[self.ID]
+		if !ok || self.Away {
+			continue
+		}
+		view := BotView{
+			Self:    self,
+			Others:  slices.Delete(slices.Clone(players), i, i+1),
+			World:   b.game.cfg,
+			Elapsed: elapsed,
+			Rand:    b.rng,
+		}
+		in, ok := bt.behavior.Think(view)
+		if !ok {
+			continue
+		}
+		bt.seq++
+		if in.HasVelocity {
+			b.game.ApplyVelocityInput(bt.id, in.Vx, in.Vy, bt.seq)
+		} else {
+			b.game.ApplyInput(bt.id, in.Key, bt.seq)
+		}
+	}
+	return joined, left
+}
+
+// prune forgets the bots that are no longer in the world (a human took
+// their place) and returns their IDs
+func (b *Bots) prune(players []models.PlayerState) []string {
+	var gone []string
+	for id := range b.bots {
+		if !slices.ContainsFunc(players, func(p models.PlayerState) bool { return p.ID == id }) {
+			gone = append(gone, id)
+			delete(b.bots, id)
+		}
+	}
+	slices.Sort(gone)
+	return gone
+}
+
+// newest returns the ID of the bot that joined last. Every bot must be in
+// players (see prune).
+func (b *Bots) newest(players []models.PlayerState) string {
+	var id string
+	num := -1
+	for _, p := range players {
+		if _, ok := b.bots[p.ID]; ok && p.PlayerNum > num {
+			id, num = p.ID, p.PlayerNum
+		}
+	}
+	return id
+}
+
+// add puts a new bot in the world with the next behavior in turn
+func (b *Bots) add() (models.PlayerState, error) {
+	names := b.cfg.Behaviors
+	if len(names) == 0 {
+		names = BehaviorNames()
+	}
+	name := names[b.next%len(names)]
+	behavior, err := NewBehavior(name, b.cfg)
+	if err != nil {
+		return models.PlayerState{}, err
+	}
+
+	b.seq++
+	x, y := b.game.GetRandomPosition()
+	player := &models.Player{
+		ID:    b.game.GenerateID(),
+		Name:  fmt.Sprintf("Bot %d", b.seq),
+		X:     x,
+		Y:     y,
+		Color: b.game.GetRandomColor(),
+		Bot:   true,
+	}
+	if err := b.game.AddPlayer(player); err != nil {
+		b.seq--
+		return models.PlayerState{}, err
+	}
+	b.next++
+	b.bots[player.ID] = &bot{id: player.ID, behavior: behavior}
+	log.Printf("Added %s (%s) playing %s", player.Name, player.ID, behavior.Name())
+
+	state, _ := b.game.PlayerState(player.ID)
+	return state, nil
+}
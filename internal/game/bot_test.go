@@ -0,0 +1,135 @@
+package game
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 기본 ThinkRate(초당 5회)에서 Update 한 번이 매번 think하도록 충분히 김
+const botThink = time.Second
+
+// newBotGame returns a game whose bots fill it up to target players
+func newBotGame(target int, behaviors ...string) *Game {
+	cfg := models.DefaultWorldConfig()
+	cfg.Bots = models.BotConfig{Target: target, Behaviors: behaviors, ThinkRate: 5}
+	return NewGame(cfg)
+}
+
+func addHuman(t *testing.T, g *Game, id string) {
+	t.Helper()
+	x, y := g.GetRandomPosition()
+	if err := g.AddPlayer(&models.Player{ID: id, X: x, Y: y}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ids(states []models.PlayerState) []string {
+	out := make([]string, len(states))
+	for i, s := range states {
+		out[i] = s.ID
+	}
+	return out
+}
+
+func TestBotsFillToTarget(t *testing.T) {
+	g := newBotGame(3, "wander")
+	if joined, _ := g.Bots().Update(100 * time.Millisecond); len(joined) != 0 {
+		t.Fatalf("%d bots joined before the think interval", len(joined))
+	}
+
+	joined, left := g.Bots().Update(botThink)
+	if len(joined) != 3 || len(left) != 0 || g.Bots().Count() != 3 {
+		t.Fatalf("joined %v, left %v, count %d, want 3 bots", ids(joined), left, g.Bots().Count())
+	}
+	players := g.GetAllPlayers()
+	for _, s := range joined {
+		if !s.Bot || !players[s.ID].Bot {
+			t.Errorf("bot %s is not marked as a bot", s.ID)
+		}
+	}
+
+	// 이미 목표 인원이면 더 들어오지 않음
+	if joined, _ := g.Bots().Update(botThink); len(joined) != 0 || len(g.GetAllPlayers()) != 3 {
+		t.Errorf("%d more bots joined a full world", len(joined))
+	}
+}
+
+func TestBotsLeaveAsHumansJoin(t *testing.T) {
+	g := newBotGame(3, "wander")
+	bots, _ := g.Bots().Update(botThink)
+
+	// 사람이 들어오면 가장 나중에 들어온 봇부터 나감
+	addHuman(t, g, "h1")
+	if _, left := g.Bots().Update(botThink); !slices.Equal(left, []string{bots[2].ID}) {
+		t.Fatalf("left %v after one human joined, want [%s]", left, bots[2].ID)
+	}
+	addHuman(t, g, "h2")
+	addHuman(t, g, "h3")
+	if _, left := g.Bots().Update(botThink); !slices.Equal(left, []string{bots[1].ID, bots[0].ID}) {
+		t.Fatalf("left %v once humans filled the world, want [%s %s]", left, bots[1].ID, bots[0].ID)
+	}
+	if n := g.Bots().Count(); n != 0 || len(g.GetAllPlayers()) != 3 {
+		t.Fatalf("%d bots and %d players, want only the 3 humans", n, len(g.GetAllPlayers()))
+	}
+
+	// 사람이 나가면 다시 채움
+	g.RemovePlayer("h3")
+	if joined, _ := g.Bots().Update(botThink); len(joined) != 1 {
+		t.Errorf("%d bots joined after a human left, want 1", len(joined))
+	}
+}
+
+func TestBotsForgetRemovedBots(t *testing.T) {
+	g := newBotGame(2, "wander")
+	bots, _ := g.Bots().Update(botThink)
+
+	// 밖에서 월드에서 빠진 봇은 left로 알리고 새 봇으로 채움
+	g.RemovePlayer(bots[0].ID)
+	joined, left := g.Bots().Update(botThink)
+	if !slices.Equal(left, []string{bots[0].ID}) || len(joined) != 1 || g.Bots().Count() != 2 {
+		t.Errorf("joined %v, left %v, count %d, want %s replaced", ids(joined), left, g.Bots().Count(), bots[0].ID)
+	}
+}
+
+func TestBotInputGoesThroughQueue(t *testing.T) {
+	g := newBotGame(3, "chase")
+	addHuman(t, g, "h")
+	bots, _ := g.Bots().Update(botThink)
+	if len(bots) != 2 {
+		t.Fatalf("%d bots joined, want 2", len(bots))
+	}
+
+	// 봇 입력은 사람 입력처럼 다음 tick을 기다림
+	g.State.Mu.Lock()
+	for _, b := range bots {
+		if in := g.inputs[b.ID]; len(in) != 1 || in[0].Seq != 1 || !in[0].HasVelocity {
+			t.Errorf("bot %s queued %+v, want one velocity input with seq 1", b.ID, in)
+		}
+	}
+	if in := g.inputs["h"]; len(in) != 0 {
+		t.Errorf("human got bot input %+v", in)
+	}
+	g.State.Mu.Unlock()
+
+	g.Tick(TickInterval)
+	for _, b := range bots {
+		if s, _ := g.PlayerState(b.ID); s.LastInputSeq != 1 {
+			t.Errorf("bot %s last input seq %d after the tick, want 1", b.ID, s.LastInputSeq)
+		}
+	}
+
+	// 자리를 비운 봇은 입력을 큐에 넣지 않음
+	g.SetAway(bots[0].ID, true)
+	g.Bots().Update(botThink)
+	g.State.Mu.Lock()
+	defer g.State.Mu.Unlock()
+	if in := g.inputs[bots[0].ID]; len(in) != 0 {
+		t.Errorf("away bot queued %+v", in)
+	}
+	if in := g.inputs[bots[1].ID]; len(in) != 1 || in[0].Seq != 2 {
+		t.Errorf("bot %s queued %+v, want seq 2", bots[1].ID, in)
+	}
+}
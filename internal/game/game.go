@@ -1,27 +1,68 @@
 package game
 
 import (
+	"errors"
+	"log"
 	"math"
 	"math/rand"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sangjinsu/websocket-multiplayer/internal/metrics"
 	"github.com/sangjinsu/websocket-multiplayer/internal/models"
 )
 
+// TickRate is the default number of physics steps per second
+const TickRate = 60
+
+// TickInterval is the default fixed physics step (1/TickRate)
+const TickInterval = time.Second / TickRate
+
 // Colors for players
 var colors = []string{"#FF6B6B", "#4ECDC4", "#45B7D1", "#96CEB4", "#FFEAA7", "#DDA0DD", "#98D8C8", "#F7DC6F"}
 
 // Game represents the game instance
 type Game struct {
-	State *models.GameState
+	State  *models.GameState
+	cfg    models.WorldConfig              // 아레나 크기/물리 상수 (생성 후 변경 없음)
+	tick   uint64                          // 지금까지 실행한 물리 tick 수 (State.Mu로 보호)
+	inputs map[string][]models.PlayerInput // 다음 tick에 적용할 입력 (State.Mu로 보호)
+	order  []*models.Player                // ID 순으로 정렬된 플레이어 (State.Mu로 보호)
+	grid   *spatialGrid                    // 충돌 broadphase (State.Mu 쓰기 잠금으로 보호)
+	round  *round                          // 게임 모드 라운드, sandbox면 nil (State.Mu로 보호)
+
+	recorder  Recorder // 녹화 중이면 변경을 받음 (State.Mu로 보호)
+	replaying bool     // 리플레이 재생용 게임 (Restore 이후), 접속자 지표에서 제외
+	bots      *Bots    // 서버 봇
 }
 
-// NewGame creates a new game instance
-func NewGame() *Game {
-	return &Game{
-		State: models.NewGameState(),
+// NewGame creates a new game instance for a world configured by cfg. The
+// game mode named by cfg.Round.Mode runs its rounds; an unknown name (which
+// config.Load rejects) falls back to the free-roam sandbox.
+func NewGame(cfg models.WorldConfig) *Game {
+	g := &Game{
+		State:  models.NewGameState(),
+		cfg:    cfg,
+		inputs: make(map[string][]models.PlayerInput),
+		grid:   newSpatialGrid(cfg.Width, cfg.Height, cfg.MinDistance()),
+	}
+	mode, err := NewMode(cfg.Round.Mode)
+	if err != nil {
+		log.Printf("%v, running the sandbox", err)
+	}
+	if mode != nil {
+		g.round = newRound(cfg.Round, mode)
 	}
+	g.bots = newBots(g, cfg.Bots)
+	return g
+}
+
+// Config returns the world configuration the game runs with
+func (g *Game) Config() models.WorldConfig {
+	return g.cfg
 }
 
 // GenerateID generates a unique player ID
@@ -39,71 +80,171 @@ func (g *Game) GetRandomColor() string {
 	return colors[rand.Intn(len(colors))]
 }
 
-// GetRandomPosition returns a random starting position that doesn't collide with other players
+// GetRandomPosition returns a random starting position that doesn't collide
+// with other players or obstacles. Maps with spawn points use a free one of
+// those first.
 func (g *Game) GetRandomPosition() (float64, float64) {
+	g.State.Mu.Lock()
+	defer g.State.Mu.Unlock()
+
+	// 시도마다 모든 플레이어를 훑지 않도록 격자를 한 번 만들어 두고 주변 칸만 검사
+	g.grid.build(g.order)
+
+	// 스폰 지점을 무작위 순서로 확인
+	spawns := g.cfg.Map.SpawnPoints
+	for _, i := range rand.Perm(len(spawns)) {
+		x, y := g.cfg.Clamp(spawns[i].X, spawns[i].Y)
+		if !g.grid.occupied(g.order, x, y, g.cfg.MinDistance()) && !blocked(g.cfg, x, y) {
+			return x, y
+		}
+	}
+
 	maxAttempts := 100
-	playerRadius := 15.0
-	minDistance := playerRadius * 2
-	
 	for attempt := 0; attempt < maxAttempts; attempt++ {
-		x := float64(rand.Intn(800))
-		y := float64(rand.Intn(600))
-		
-		// Check bounds
-		if x < 15 || x > 785 || y < 15 || y > 585 {
-			continue
-		}
-		
-		// Check collision with other players
-		collision := false
-		for _, player := range g.State.Players {
-			dx := x - player.X
-			dy := y - player.Y
-			distance := dx*dx + dy*dy
-			
-			if distance < minDistance*minDistance {
-				collision = true
-				break
-			}
-		}
-		
-		if !collision {
+		// 플레이어 몸 전체가 아레나 안에 들어오는 범위에서 선택
+		r := g.cfg.PlayerRadius
+		x := r + rand.Float64()*(g.cfg.Width-2*r)
+		y := r + rand.Float64()*(g.cfg.Height-2*r)
+
+		// Check collision with other players and obstacles
+		if !g.grid.occupied(g.order, x, y, g.cfg.MinDistance()) && !blocked(g.cfg, x, y) {
 			return x, y
 		}
 	}
-	
+
 	// If no valid position found after max attempts, return center
-	return 400, 300
+	return g.cfg.Width / 2, g.cfg.Height / 2
 }
 
-// AddPlayer adds a player to the game
-func (g *Game) AddPlayer(player *models.Player) {
+// ErrGameFull is returned by AddPlayer when the world already holds
+// WorldConfig.MaxPlayers players
+var ErrGameFull = errors.New("game is full")
+
+// ErrNotPlaying is returned for input from a player who isn't in the world
+// or is away waiting for a reconnect
+var ErrNotPlaying = errors.New("player is not playing")
+
+// AddPlayer adds a player to the game. In a full world a bot leaves to make
+// room for a human.
+func (g *Game) AddPlayer(player *models.Player) error {
 	g.State.Mu.Lock()
 	defer g.State.Mu.Unlock()
-	
+
+	if g.cfg.MaxPlayers > 0 && len(g.State.Players) >= g.cfg.MaxPlayers {
+		if player.Bot || !g.dropBot() {
+			return ErrGameFull
+		}
+	}
+	g.addPlayer(player, time.Now())
+	g.joinEvent(player)
+	return nil
+}
+
+// addPlayer adds a player that joined at joinedAt. The caller must hold
+// State.Mu.
+func (g *Game) addPlayer(player *models.Player, joinedAt time.Time) {
 	// Assign player number
 	g.State.PlayerCount++
 	player.PlayerNum = g.State.PlayerCount
-	
+
 	// Keep the original name as provided by the user
 	// Don't override with default names
-	
-	player.JoinedAt = time.Now()
+
+	player.JoinedAt = joinedAt
 	player.LastSeen = time.Now()
-	
+
 	g.State.Players[player.ID] = player
+
+	// ID 순서를 유지하며 삽입 (tick마다 정렬하지 않도록)
+	i, found := slices.BinarySearchFunc(g.order, player.ID, comparePlayerID)
+	if found {
+		g.order[i] = player
+	} else {
+		g.order = slices.Insert(g.order, i, player)
+	}
+
+	if g.round != nil {
+		g.round.mode.OnJoin(player, g.order)
+	}
+	if !g.replaying {
+		playerGauge(player).Inc()
+	}
+}
+
+// playerGauge returns the metric that counts players like p
+func playerGauge(p *models.Player) prometheus.Gauge {
+	if p.Bot {
+		return metrics.Bots
+	}
+	return metrics.Players
+}
+
+// Full reports whether the world has no room for another human player (bots
+// make way for humans, see AddPlayer)
+func (g *Game) Full() bool {
+	g.State.Mu.RLock()
+	defer g.State.Mu.RUnlock()
+	return g.cfg.MaxPlayers > 0 && len(g.State.Players)-g.botCount() >= g.cfg.MaxPlayers
+}
+
+// botCount returns how many players are bots. The caller must hold
+// State.Mu.
+func (g *Game) botCount() int {
+	n := 0
+	for _, p := range g.order {
+		if p.Bot {
+			n++
+		}
+	}
+	return n
+}
+
+// dropBot removes the bot that joined last and reports whether there was
+// one. The caller must hold State.Mu.
+func (g *Game) dropBot() bool {
+	var last *models.Player
+	for _, p := range g.order {
+		if p.Bot && (last == nil || p.PlayerNum > last.PlayerNum) {
+			last = p
+		}
+	}
+	if last == nil {
+		return false
+	}
+	g.removePlayer(last.ID)
+	return true
+}
+
+func comparePlayerID(p *models.Player, id string) int {
+	return strings.Compare(p.ID, id)
 }
 
 // RemovePlayer removes a player from the game
 func (g *Game) RemovePlayer(playerID string) {
 	g.State.Mu.Lock()
 	defer g.State.Mu.Unlock()
-	
-	if _, exists := g.State.Players[playerID]; exists {
+	g.removePlayer(playerID)
+}
+
+// removePlayer is RemovePlayer for callers holding State.Mu
+func (g *Game) removePlayer(playerID string) {
+	if player, exists := g.State.Players[playerID]; exists {
 		delete(g.State.Players, playerID)
-		
+		delete(g.inputs, playerID)
+		if i, found := slices.BinarySearchFunc(g.order, playerID, comparePlayerID); found {
+			g.order = slices.Delete(g.order, i, i+1)
+		}
+
 		// Reorder remaining players
 		g.reorderPlayers()
+
+		if g.round != nil {
+			g.round.mode.OnLeave(player, g.order)
+		}
+		if !g.replaying {
+			playerGauge(player).Dec()
+		}
+		g.record(models.ReplayEvent{Kind: models.ReplayLeave, Player: playerID})
 	}
 }
 
@@ -114,17 +255,17 @@ func (g *Game) reorderPlayers() {
 		id       string
 		joinedAt time.Time
 	}
-	
+
 	var players []playerInfo
 	for id, player := range g.State.Players {
 		players = append(players, playerInfo{id: id, joinedAt: player.JoinedAt})
 	}
-	
+
 	// Sort by join time
 	sort.Slice(players, func(i, j int) bool {
 		return players[i].joinedAt.Before(players[j].joinedAt)
 	})
-	
+
 	// Reassign player numbers only (keep original names)
 	for i, playerInfo := range players {
 		if player, exists := g.State.Players[playerInfo.id]; exists {
@@ -132,7 +273,7 @@ func (g *Game) reorderPlayers() {
 			// Keep the original name, don't change it
 		}
 	}
-	
+
 	g.State.PlayerCount = len(g.State.Players)
 }
 
@@ -143,129 +284,171 @@ func (g *Game) GetPlayer(playerID string) *models.Player {
 	return g.State.Players[playerID]
 }
 
-// 입력 큐: playerID -> 최근 입력
-var inputQueue = make(map[string]string)
-
-// ApplyInput: WASD 입력에 따라 속도 변경
-func (g *Game) ApplyInput(playerID, key string) {
+// SetAway marks a player whose connection dropped (or came back). An away
+// player keeps its place in the world but stops moving on its own and
+// ignores input; other players can still bump it.
+func (g *Game) SetAway(playerID string, away bool) {
 	g.State.Mu.Lock()
 	defer g.State.Mu.Unlock()
-	p, ok := g.State.Players[playerID]
-	if !ok {
-		return
+
+	if player, exists := g.State.Players[playerID]; exists {
+		player.Away = away
+		if away {
+			player.Vx, player.Vy = 0, 0
+			delete(g.inputs, playerID)
+		}
+		player.LastSeen = time.Now()
+		g.record(models.ReplayEvent{Kind: models.ReplayAway, Player: playerID, Away: away})
 	}
-	const speed = 2.5
-	switch key {
-	case "w":
-		p.Vy -= speed
-	case "s":
-		p.Vy += speed
-	case "a":
-		p.Vx -= speed
-	case "d":
-		p.Vx += speed
+}
+
+// playerStates returns a consistent snapshot of every player, in ID order
+func (g *Game) playerStates() []models.PlayerState {
+	g.State.Mu.RLock()
+	defer g.State.Mu.RUnlock()
+	states := make([]models.PlayerState, len(g.order))
+	for i, p := range g.order {
+		states[i] = p.State()
 	}
+	return states
 }
 
-// ApplyVelocityInput: 터치/클릭 이동을 위한 속도 직접 적용
-func (g *Game) ApplyVelocityInput(playerID string, vx, vy float64) {
+// PlayerState returns a consistent snapshot of one player
+func (g *Game) PlayerState(playerID string) (models.PlayerState, bool) {
+	g.State.Mu.RLock()
+	defer g.State.Mu.RUnlock()
+	if player, exists := g.State.Players[playerID]; exists {
+		return player.State(), true
+	}
+	return models.PlayerState{}, false
+}
+
+// 플레이어당 대기할 수 있는 최대 입력 수 (넘치면 가장 오래된 입력부터 버림)
+const maxQueuedInputs = 32
+
+// QueueInput queues a client input; it is applied at the start of the next tick
+func (g *Game) QueueInput(playerID string, in models.PlayerInput) error {
 	g.State.Mu.Lock()
 	defer g.State.Mu.Unlock()
-	p, ok := g.State.Players[playerID]
-	if !ok {
-		return
-	}
-	
-	// 기존 속도에 새로운 속도 추가 (부드러운 이동을 위해)
-	const blendFactor = 0.3 // 기존 속도와 새로운 속도의 혼합 비율
-	p.Vx = p.Vx*(1-blendFactor) + vx*blendFactor
-	p.Vy = p.Vy*(1-blendFactor) + vy*blendFactor
-	
-	// 속도 제한 (너무 빠르지 않도록)
-	const maxSpeed = 8.0
-	if p.Vx > maxSpeed {
-		p.Vx = maxSpeed
-	} else if p.Vx < -maxSpeed {
-		p.Vx = -maxSpeed
+	if p, ok := g.State.Players[playerID]; !ok || p.Away {
+		return ErrNotPlaying
 	}
-	if p.Vy > maxSpeed {
-		p.Vy = maxSpeed
-	} else if p.Vy < -maxSpeed {
-		p.Vy = -maxSpeed
+	queue := append(g.inputs[playerID], in)
+	if len(queue) > maxQueuedInputs {
+		queue = queue[len(queue)-maxQueuedInputs:]
 	}
+	g.inputs[playerID] = queue
+	g.record(inputEvent(playerID, in))
+	return nil
 }
 
-// Tick: 모든 플레이어의 위치/속도/충돌/반동 등 물리 연산 수행
-func (g *Game) Tick() {
+// ApplyInput: WASD 입력을 다음 tick에 속도로 반영하도록 큐에 넣음
+func (g *Game) ApplyInput(playerID, key string, seq uint32) error {
+	return g.QueueInput(playerID, models.PlayerInput{Seq: seq, Key: key})
+}
+
+// ApplyVelocityInput: 터치/클릭 이동 속도를 다음 tick에 반영하도록 큐에 넣음
+func (g *Game) ApplyVelocityInput(playerID string, vx, vy float64, seq uint32) error {
+	return g.QueueInput(playerID, models.PlayerInput{Seq: seq, HasVelocity: true, Vx: vx, Vy: vy})
+}
+
+// 이보다 약한 충돌(붙어서 미는 중 등, px/s)은 이벤트로 내보내지 않음
+const minEventImpulse = 6.0
+
+// Tick: 대기 중인 입력을 적용한 뒤 물리 연산(Step)을 dt만큼 수행.
+// 같은 결과를 얻으려면 dt는 항상 같은 고정 값이어야 한다.
+// 이번 tick에 일어난 충돌 이벤트를 반환한다.
+func (g *Game) Tick(dt time.Duration) []models.CollisionEvent {
 	g.State.Mu.Lock()
 	defer g.State.Mu.Unlock()
-	const (
-		friction = 0.98
-		playerRadius = 15.0
-		minDistance = playerRadius * 2
-		w = 800.0
-		h = 600.0
-	)
-	// 1. 속도 적용 및 마찰
-	for _, p := range g.State.Players {
-		p.X += p.Vx
-		p.Y += p.Vy
-		p.Vx *= friction
-		p.Vy *= friction
-		// 2. 경계 처리
-		if p.X < playerRadius {
-			p.X = playerRadius
-			p.Vx *= -0.7
-		}
-		if p.X > w-playerRadius {
-			p.X = w - playerRadius
-			p.Vx *= -0.7
-		}
-		if p.Y < playerRadius {
-			p.Y = playerRadius
-			p.Vy *= -0.7
-		}
-		if p.Y > h-playerRadius {
-			p.Y = h - playerRadius
-			p.Vy *= -0.7
+	// 잠금을 기다린 시간은 빼고 step 자체만 잰다
+	start := time.Now()
+	defer func() { metrics.TickDuration.Observe(time.Since(start).Seconds()) }()
+	g.tick++
+
+	// 1. tick 경계에서 입력 적용 (플레이어 ID 순, 도착 순)
+	for _, p := range g.order {
+		for _, in := range g.inputs[p.ID] {
+			ApplyPlayerInput(g.cfg, p, in)
 		}
 	}
-	// 3. 플레이어 간 충돌(탄성)
-	players := g.State.Players
-	for idA, a := range players {
-		for idB, b := range players {
-			if idA >= idB {
-				continue
-			}
-			dx := b.X - a.X
-			dy := b.Y - a.Y
-			dist := math.Sqrt(dx*dx + dy*dy)
-			if dist < minDistance && dist > 0 {
-				overlap := minDistance - dist
-				// 각자 반씩 밀어내기
-				pushX := (dx / dist) * (overlap / 2)
-				pushY := (dy / dist) * (overlap / 2)
-				a.X -= pushX
-				a.Y -= pushY
-				b.X += pushX
-				b.Y += pushY
-				// 탄성 충돌(속도 교환)
-				nx, ny := dx/dist, dy/dist
-				va := a.Vx*nx + a.Vy*ny
-				vb := b.Vx*nx + b.Vy*ny
-				a.Vx += (vb - va) * nx
-				a.Vy += (vb - va) * ny
-				b.Vx += (va - vb) * nx
-				b.Vy += (va - vb) * ny
+	clear(g.inputs)
+
+	// 2. 물리 연산
+	collisions := step(g.cfg, g.order, g.grid, dt)
+
+	// 3. 게임 모드: 진행 중이면 접촉을 전달한 뒤 라운드를 진행
+	if g.round != nil {
+		if g.round.phase == models.PhasePlaying {
+			for _, ev := range collisions {
+				a, b := g.State.Players[ev.A], g.State.Players[ev.B]
+				g.round.mode.OnCollision(a, b)
 			}
 		}
+		g.round.advance(g.order, dt)
+	}
+	g.recordCheck()
+
+	// 4. 클라이언트에 알릴 만한 충돌만 골라 tick 번호를 붙임
+	events := collisions[:0]
+	for _, ev := range collisions {
+		if ev.Impulse >= minEventImpulse {
+			ev.Tick = g.tick
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// RoundState returns the current round, or false in the sandbox
+func (g *Game) RoundState() (models.RoundState, bool) {
+	g.State.Mu.RLock()
+	defer g.State.Mu.RUnlock()
+	if g.round == nil {
+		return models.RoundState{}, false
+	}
+	return g.round.state(), true
+}
+
+// TakeRoundChanges returns the phase changes since the last call, oldest first
+func (g *Game) TakeRoundChanges() []models.RoundState {
+	g.State.Mu.Lock()
+	defer g.State.Mu.Unlock()
+	if g.round == nil || len(g.round.changes) == 0 {
+		return nil
 	}
+	changes := g.round.changes
+	g.round.changes = nil
+	return changes
+}
+
+// TickCount returns the number of ticks run so far; it only ever increases,
+// except when Restore rewinds the game
+func (g *Game) TickCount() uint64 {
+	g.State.Mu.RLock()
+	defer g.State.Mu.RUnlock()
+	return g.tick
 }
 
 // GetAllPlayers returns all players (순수 데이터만)
 func (g *Game) GetAllPlayers() map[string]*models.Player {
 	g.State.Mu.RLock()
 	defer g.State.Mu.RUnlock()
+	return g.copyPlayers()
+}
+
+// Dump returns a copy of the whole game state, without connections
+func (g *Game) Dump() *models.GameState {
+	g.State.Mu.RLock()
+	defer g.State.Mu.RUnlock()
+	st := models.NewGameState()
+	st.Players = g.copyPlayers()
+	st.PlayerCount = g.State.PlayerCount
+	return st
+}
+
+// copyPlayers copies every player's data. The caller must hold State.Mu.
+func (g *Game) copyPlayers() map[string]*models.Player {
 	players := make(map[string]*models.Player, len(g.State.Players))
 	for id, p := range g.State.Players {
 		players[id] = &models.Player{
@@ -279,66 +462,67 @@ func (g *Game) GetAllPlayers() map[string]*models.Player {
 			Color:     p.Color,
 			JoinedAt:  p.JoinedAt,
 			LastSeen:  p.LastSeen,
+
+			LastInputSeq: p.LastInputSeq,
+			Away:         p.Away,
+			It:           p.It,
+			Score:        p.Score,
+			Bot:          p.Bot,
 		}
 	}
 	return players
 }
 
-// UpdatePlayerPosition updates a player's position with bounce collision
-func (g *Game) UpdatePlayerPosition(playerID string, x, y float64) {
+// UpdatePlayerPosition updates a player's position with bounce collision and
+// reports whether the player was moved
+func (g *Game) UpdatePlayerPosition(playerID string, x, y float64) bool {
 	g.State.Mu.Lock()
 	defer g.State.Mu.Unlock()
-	
+
 	if player, exists := g.State.Players[playerID]; exists {
-		// Check if the new position is within bounds (canvas: 800x600, player radius: 15)
-		if x < 15 || x > 785 || y < 15 || y > 585 {
+		// Check if the new position is within bounds
+		if cx, cy := g.cfg.Clamp(x, y); cx != x || cy != y {
 			// Position is outside bounds, don't update
-			return
+			return false
 		}
-		
+		if blocked(g.cfg, x, y) {
+			// 장애물과 겹치는 위치로는 이동하지 않음
+			return false
+		}
+
 		// Check collision with other players and calculate bounce
-		playerRadius := 15.0
-		minDistance := playerRadius * 2 // Minimum distance between player centers
+		minDistance := g.cfg.MinDistance() // Minimum distance between player centers
 		bounceX := x
 		bounceY := y
 		hasCollision := false
-		
+
 		for id, otherPlayer := range g.State.Players {
 			if id == playerID {
 				continue // Skip self
 			}
-			
+
 			// Calculate distance between players
 			dx := x - otherPlayer.X
 			dy := y - otherPlayer.Y
 			distance := dx*dx + dy*dy // Using squared distance for efficiency
-			
+
 			if distance < minDistance*minDistance {
 				hasCollision = true
-				
+
 				// Calculate bounce direction
 				angle := math.Atan2(dy, dx)
-				
+
 				// Calculate bounce position
 				bounceX = otherPlayer.X + math.Cos(angle)*minDistance
 				bounceY = otherPlayer.Y + math.Sin(angle)*minDistance
-				
+
 				// Keep bounce position within bounds
-				if bounceX < 15 {
-					bounceX = 15
-				} else if bounceX > 785 {
-					bounceX = 785
-				}
-				if bounceY < 15 {
-					bounceY = 15
-				} else if bounceY > 585 {
-					bounceY = 585
-				}
-				
+				bounceX, bounceY = g.cfg.Clamp(bounceX, bounceY)
+
 				break // Handle first collision only
 			}
 		}
-		
+
 		if hasCollision {
 			// Update to bounce position
 			player.X = bounceX
@@ -348,7 +532,10 @@ func (g *Game) UpdatePlayerPosition(playerID string, x, y float64) {
 			player.X = x
 			player.Y = y
 		}
-		
+
 		player.LastSeen = time.Now()
+		g.record(models.ReplayEvent{Kind: models.ReplayTeleport, Player: playerID, X: player.X, Y: player.Y})
+		return true
 	}
-} 
\ No newline at end of file
+	return false
+}
@@ -0,0 +1,43 @@
+package game
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// go test -bench Tick ./internal/game
+func BenchmarkTick(b *testing.B) {
+	for _, n := range []int{100, 500, 2000} {
+		b.Run(fmt.Sprintf("players=%d", n), func(b *testing.B) {
+			g := newPopulatedGame(n, 1)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				g.Tick(TickInterval)
+			}
+		})
+	}
+}
+
+// newPopulatedGame returns a game with n players scattered uniformly over the
+// world, moving in random directions. Positions are not de-overlapped, so the
+// larger counts model a crowded room.
+func newPopulatedGame(n int, seed uint64) *Game {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	cfg := models.DefaultWorldConfig()
+	g := NewGame(cfg)
+	for i := 0; i < n; i++ {
+		x, y := cfg.Clamp(rng.Float64()*cfg.Width, rng.Float64()*cfg.Height)
+		_ = g.AddPlayer(&models.Player{
+			ID: fmt.Sprintf("p%05d", i),
+			X:  x,
+			Y:  y,
+			Vx: rng.Float64()*600 - 300,
+			Vy: rng.Float64()*600 - 300,
+		})
+	}
+	return g
+}
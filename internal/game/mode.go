@@ -0,0 +1,157 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// GameMode adds rules on top of the free-roam physics. Game calls it with
+// State.Mu held, from the tick loop and from AddPlayer/RemovePlayer, so a
+// mode must not call back into Game. players is every player in ID order,
+// including away ones; a mode may change their It and Score.
+type GameMode interface {
+	// Name is the mode's config name, e.g. "tag"
+	Name() string
+	// OnJoin is called after p was added to the world
+	OnJoin(p *models.Player, players []*models.Player)
+	// OnLeave is called after p was removed from the world
+	OnLeave(p *models.Player, players []*models.Player)
+	// OnPhase is called when the round enters a new phase
+	OnPhase(phase models.RoundPhase, players []*models.Player)
+	// OnTick is called after every physics step while the round is playing
+	OnTick(players []*models.Player, dt time.Duration)
+	// OnCollision is called for every player-player contact resolved in a
+	// step while the round is playing, weak ones included
+	OnCollision(a, b *models.Player)
+}
+
+// replayable is implemented by modes that use randomness or keep state of
+// their own, so a replay can seed them and save and restore that state
+// (see Game.StartRecording and Game.Restore)
+type replayable interface {
+	seed(seed int64)
+	saveState() ([]byte, error)
+	loadState(data []byte) error
+}
+
+// 설정 이름 -> 게임 모드 생성자
+var modes = map[string]func() GameMode{
+	"tag": func() GameMode { return NewTagMode() },
+}
+
+// NewMode returns a new instance of the named mode, or nil for "" (the
+// free-roam sandbox)
+func NewMode(name string) (GameMode, error) {
+	if name == "" {
+		return nil, nil
+	}
+	newMode, ok := modes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown game mode %q", name)
+	}
+	return newMode(), nil
+}
+
+// round is the round state machine:
+// waiting → countdown → playing → results → reset → waiting.
+// It is only used with State.Mu held.
+type round struct {
+	cfg    models.RoundConfig
+	mode   GameMode
+	phase  models.RoundPhase
+	number int
+	left   time.Duration // 이 phase의 남은 시간 (countdown/playing/results)
+
+	results []models.RoundResult // 마지막 results phase의 점수
+	changes []models.RoundState  // 아직 전달하지 않은 phase 변경
+}
+
+func newRound(cfg models.RoundConfig, mode GameMode) *round {
+	return &round{cfg: cfg, mode: mode, phase: models.PhaseWaiting, number: 1}
+}
+
+// enter switches to phase, lets the mode react and queues the change
+func (r *round) enter(phase models.RoundPhase, length time.Duration, players []*models.Player) {
+	r.phase = phase
+	r.left = length
+	r.mode.OnPhase(phase, players)
+	r.changes = append(r.changes, r.state())
+}
+
+// state returns the round as sent to clients
+func (r *round) state() models.RoundState {
+	st := models.RoundState{
+		Mode:      r.mode.Name(),
+		Phase:     r.phase,
+		Round:     r.number,
+		Remaining: r.left.Seconds(),
+	}
+	if r.phase == models.PhaseResults {
+		st.Results = r.results
+	}
+	return st
+}
+
+// advance moves the round on by one step of dt
+func (r *round) advance(players []*models.Player, dt time.Duration) {
+	active := 0
+	for _, p := range players {
+		if !p.Away {
+			active++
+		}
+	}
+	enough := active >= r.cfg.MinPlayers
+
+	switch r.phase {
+	case models.PhaseWaiting:
+		if enough {
+			r.enter(models.PhaseCountdown, r.cfg.Countdown(), players)
+		}
+
+	case models.PhaseCountdown:
+		r.left -= dt
+		switch {
+		case !enough:
+			r.enter(models.PhaseWaiting, 0, players)
+		case r.left <= 0:
+			r.enter(models.PhasePlaying, r.cfg.Length(), players)
+		}
+
+	case models.PhasePlaying:
+		r.mode.OnTick(players, dt)
+		r.left -= dt
+		// 시간이 다 되거나 인원이 모자라면 라운드 종료
+		if r.left <= 0 || !enough {
+			r.results = scoreboard(players)
+			r.enter(models.PhaseResults, r.cfg.Results(), players)
+		}
+
+	case models.PhaseResults:
+		r.left -= dt
+		if r.left <= 0 {
+			for _, p := range players {
+				p.It = false
+				p.Score = 0
+			}
+			r.enter(models.PhaseReset, 0, players)
+			r.number++
+			r.results = nil
+			r.enter(models.PhaseWaiting, 0, players)
+		}
+	}
+}
+
+// scoreboard returns the players' scores, highest first
+func scoreboard(players []*models.Player) []models.RoundResult {
+	results := make([]models.RoundResult, 0, len(players))
+	for _, p := range players {
+		results = append(results, models.RoundResult{ID: p.ID, Name: p.Name, Score: p.Score})
+	}
+	slices.SortStableFunc(results, func(a, b models.RoundResult) int {
+		return b.Score - a.Score
+	})
+	return results
+}
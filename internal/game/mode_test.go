@@ -0,0 +1,115 @@
+package game
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 가짜 시계: tick마다 정확히 이만큼 흐르므로 phase 경계가 tick에 딱 맞음
+const roundStep = 250 * time.Millisecond
+
+// phaseChange is a round_phase change and the tick it happened on
+type phaseChange struct {
+	tick      uint64
+	phase     models.RoundPhase
+	round     int
+	remaining float64
+}
+
+// newRoundGame returns a tag game with a 1s countdown, 2s rounds and 1s of
+// results, and players far enough apart never to touch
+func newRoundGame(t *testing.T, ids ...string) *Game {
+	t.Helper()
+	cfg := models.DefaultWorldConfig()
+	cfg.Round = models.RoundConfig{Mode: "tag", MinPlayers: 2, CountdownSecs: 1, RoundSecs: 2, ResultsSecs: 1}
+	g := NewGame(cfg)
+	for i, id := range ids {
+		if err := g.AddPlayer(&models.Player{ID: id, X: 100 + 200*float64(i), Y: 100}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g
+}
+
+// runRound runs n ticks and returns the phase changes they made
+func runRound(g *Game, n int) []phaseChange {
+	var changes []phaseChange
+	for range n {
+		g.Tick(roundStep)
+		for _, st := range g.TakeRoundChanges() {
+			changes = append(changes, phaseChange{g.TickCount(), st.Phase, st.Round, st.Remaining})
+		}
+	}
+	return changes
+}
+
+func TestRoundLifecycle(t *testing.T) {
+	g := newRoundGame(t, "a")
+	if got := runRound(g, 4); len(got) != 0 {
+		t.Fatalf("one player started a round: %v", got)
+	}
+
+	if err := g.AddPlayer(&models.Player{ID: "b", X: 500, Y: 100}); err != nil {
+		t.Fatal(err)
+	}
+	got := runRound(g, 1+4+8+4+1)
+	want := []phaseChange{
+		{5, models.PhaseCountdown, 1, 1},
+		{9, models.PhasePlaying, 1, 2},
+		{17, models.PhaseResults, 1, 1},
+		{21, models.PhaseReset, 1, 0},
+		{21, models.PhaseWaiting, 2, 0},
+		{22, models.PhaseCountdown, 2, 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("phase changes\n got %v\nwant %v", got, want)
+	}
+	for _, p := range g.GetAllPlayers() {
+		if p.It || p.Score != 0 {
+			t.Errorf("player %s kept it=%v score=%d after the reset", p.ID, p.It, p.Score)
+		}
+	}
+}
+
+func TestRoundResults(t *testing.T) {
+	g := newRoundGame(t, "a", "b", "c")
+	runRound(g, 1+4+8)
+
+	st, _ := g.RoundState()
+	if st.Phase != models.PhaseResults || len(st.Results) != 3 {
+		t.Fatalf("round %+v, want results for 3 players", st)
+	}
+	// 술래 한 명은 점수가 낮고 나머지는 2초 동안 안전했음
+	if !slices.IsSortedFunc(st.Results, func(a, b models.RoundResult) int { return b.Score - a.Score }) {
+		t.Errorf("results %v not sorted by score", st.Results)
+	}
+	if top := st.Results[0].Score; top != 2 {
+		t.Errorf("best score %d, want 2", top)
+	}
+}
+
+func TestCountdownStopsWithoutEnoughPlayers(t *testing.T) {
+	g := newRoundGame(t, "a", "b")
+	runRound(g, 2)
+	g.SetAway("b", true)
+
+	got := runRound(g, 8)
+	want := []phaseChange{{3, models.PhaseWaiting, 1, 0}}
+	if !slices.Equal(got, want) {
+		t.Errorf("phase changes after a player went away\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestRoundEndsWhenPlayersLeave(t *testing.T) {
+	g := newRoundGame(t, "a", "b")
+	runRound(g, 1+4+2)
+	g.RemovePlayer("b")
+
+	got := runRound(g, 1)
+	if len(got) != 1 || got[0].phase != models.PhaseResults {
+		t.Errorf("phase changes after a player left %v, want results", got)
+	}
+}
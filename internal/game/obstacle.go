@@ -0,0 +1,54 @@
+package game
+
+import (
+	"math"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// collideObstacles pushes p out of every obstacle it overlaps and bounces it
+// off the surface the same way walls do: the velocity component into the
+// surface is reversed and scaled by cfg.Restitution. Maps have a handful of
+// obstacles, so they are simply all checked.
+func collideObstacles(cfg models.WorldConfig, p *models.Player) {
+	radius := cfg.PlayerRadius
+	for _, o := range cfg.Map.Obstacles {
+		cx, cy, inside := o.Closest(p.X, p.Y)
+		dx := p.X - cx
+		dy := p.Y - cy
+		dist := math.Sqrt(dx*dx + dy*dy)
+		if dist == 0 || (!inside && dist >= radius) {
+			// 정확히 표면 위인 경우는 방향을 알 수 없으므로 다음 tick에 처리
+			continue
+		}
+
+		// 바깥쪽 법선과 밀어낼 거리 (중심이 안쪽이면 표면까지 + 반지름)
+		nx, ny := dx/dist, dy/dist
+		push := radius - dist
+		if inside {
+			nx, ny = -nx, -ny
+			push = radius + dist
+		}
+		p.X += nx * push
+		p.Y += ny * push
+
+		// 표면으로 향하는 속도 성분만 반사
+		if vn := p.Vx*nx + p.Vy*ny; vn < 0 {
+			p.Vx -= (1 + cfg.Restitution) * vn * nx
+			p.Vy -= (1 + cfg.Restitution) * vn * ny
+		}
+	}
+}
+
+// blocked reports whether a player centered at (x, y) would overlap an obstacle
+func blocked(cfg models.WorldConfig, x, y float64) bool {
+	for _, o := range cfg.Map.Obstacles {
+		cx, cy, inside := o.Closest(x, y)
+		dx := x - cx
+		dy := y - cy
+		if inside || dx*dx+dy*dy < cfg.PlayerRadius*cfg.PlayerRadius {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"math"
+	"testing"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// obstacleWorld has one 100x100 box at (100, 100) and players of radius 15
+func obstacleWorld() models.WorldConfig {
+	cfg := models.DefaultWorldConfig()
+	cfg.PlayerRadius = 15
+	cfg.Restitution = 0.5
+	cfg.Map.Obstacles = []models.Obstacle{{Shape: models.ShapeRect, X: 100, Y: 100, W: 100, H: 100}}
+	return cfg
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestCollideObstaclesPushesOutOfBox(t *testing.T) {
+	tests := []struct {
+		name         string
+		in           models.Player
+		x, y, vx, vy float64
+	}{
+		{"left", models.Player{X: 90, Y: 150, Vx: 200}, 85, 150, -100, 0},
+		{"right", models.Player{X: 210, Y: 150, Vx: -200}, 215, 150, 100, 0},
+		{"top", models.Player{X: 150, Y: 90, Vy: 200}, 150, 85, 0, -100},
+		{"bottom", models.Player{X: 150, Y: 210, Vy: -200}, 150, 215, 0, 100},
+		// 중심이 상자 안이면 가장 가까운 변 밖으로
+		{"inside near left", models.Player{X: 105, Y: 150, Vx: 200}, 85, 150, -100, 0},
+		{"inside near bottom", models.Player{X: 150, Y: 195, Vy: -200}, 150, 215, 0, 100},
+		// 이미 멀어지는 중이면 속도는 그대로
+		{"moving away", models.Player{X: 90, Y: 150, Vx: -200, Vy: 50}, 85, 150, -200, 50},
+		// 겹치지 않으면 그대로
+		{"clear", models.Player{X: 80, Y: 150, Vx: 200}, 80, 150, 200, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.in
+			collideObstacles(obstacleWorld(), &p)
+			if !near(p.X, tt.x) || !near(p.Y, tt.y) || !near(p.Vx, tt.vx) || !near(p.Vy, tt.vy) {
+				t.Errorf("got (%g, %g) moving (%g, %g), want (%g, %g) moving (%g, %g)",
+					p.X, p.Y, p.Vx, p.Vy, tt.x, tt.y, tt.vx, tt.vy)
+			}
+		})
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	cfg := obstacleWorld()
+	tests := []struct {
+		x, y float64
+		want bool
+	}{
+		{150, 150, true},  // 안
+		{86, 150, true},   // 변에서 반지름보다 가까움
+		{85, 150, false},  // 정확히 닿음
+		{90, 90, true},    // 모서리까지 약 14.1
+		{88, 88, false},   // 모서리까지 약 17
+		{400, 300, false}, // 멀리
+	}
+	for _, tt := range tests {
+		if got := blocked(cfg, tt.x, tt.y); got != tt.want {
+			t.Errorf("blocked(%g, %g) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestSpawnSkipsBlockedPoints(t *testing.T) {
+	cfg := obstacleWorld()
+	cfg.Map.SpawnPoints = []models.Point{{X: 150, Y: 150}, {X: 90, Y: 150}, {X: 400, Y: 300}}
+	g := NewGame(cfg)
+	// 스폰 지점은 무작위 순서로 보므로 여러 번 확인
+	for range 20 {
+		if x, y := g.GetRandomPosition(); x != 400 || y != 300 {
+			t.Fatalf("spawned at (%g, %g), want the only clear spawn point (400, 300)", x, y)
+		}
+	}
+
+	// 스폰 지점이 모두 막히면 장애물 밖의 무작위 위치
+	cfg.Map.SpawnPoints = cfg.Map.SpawnPoints[:2]
+	g = NewGame(cfg)
+	for range 20 {
+		if x, y := g.GetRandomPosition(); blocked(cfg, x, y) {
+			t.Fatalf("spawned at (%g, %g), inside the box", x, y)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package game
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 마찰 계수(WorldConfig.Friction)는 1/frictionRate초마다 곱해지는 값.
+// 속도 단위는 px/s, 시간 단위는 초.
+const frictionRate = 60.0
+
+// ApplyKey changes p's velocity for a WASD key
+func ApplyKey(cfg models.WorldConfig, p *models.Player, key string) {
+	switch key {
+	case "w":
+		p.Vy -= cfg.KeySpeed
+	case "s":
+		p.Vy += cfg.KeySpeed
+	case "a":
+		p.Vx -= cfg.KeySpeed
+	case "d":
+		p.Vx += cfg.KeySpeed
+	}
+}
+
+// ApplyVelocity blends a touch/click velocity into p's velocity
+func ApplyVelocity(cfg models.WorldConfig, p *models.Player, vx, vy float64) {
+	// 기존 속도에 새로운 속도 추가 (부드러운 이동을 위해)
+	p.Vx = p.Vx*(1-cfg.BlendFactor) + vx*cfg.BlendFactor
+	p.Vy = p.Vy*(1-cfg.BlendFactor) + vy*cfg.BlendFactor
+
+	// 속도 제한 (너무 빠르지 않도록)
+	p.Vx = math.Max(-cfg.MaxSpeed, math.Min(cfg.MaxSpeed, p.Vx))
+	p.Vy = math.Max(-cfg.MaxSpeed, math.Min(cfg.MaxSpeed, p.Vy))
+}
+
+// ApplyPlayerInput applies one queued input to p and records its sequence number
+func ApplyPlayerInput(cfg models.WorldConfig, p *models.Player, in models.PlayerInput) {
+	if in.Key != "" {
+		ApplyKey(cfg, p, in.Key)
+	}
+	if in.HasVelocity {
+		ApplyVelocity(cfg, p, in.Vx, in.Vy)
+	}
+	if in.Seq > p.LastInputSeq {
+		p.LastInputSeq = in.Seq
+	}
+}
+
+// Step advances the players by dt in a world configured by cfg: velocity
+// and friction, wall and obstacle bounces, then elastic player-player
+// collisions, and returns one event per resolved collision. It takes no
+// locks and has no other inputs, so clients and tests can replay inputs
+// through exactly the same physics the server runs, as long as they use the
+// server's fixed step for dt. Players are processed in ID order so the
+// result doesn't depend on map iteration.
+func Step(cfg models.WorldConfig, players map[string]*models.Player, dt time.Duration) []models.CollisionEvent {
+	return step(cfg, sortedPlayers(players), newSpatialGrid(cfg.Width, cfg.Height, cfg.MinDistance()), dt)
+}
+
+// step is Step for players already in ID order. Collision candidates come
+// from grid, built after movement; a pair that only starts overlapping
+// because of another push this tick is resolved on the next one.
+func step(cfg models.WorldConfig, ordered []*models.Player, grid *spatialGrid, dt time.Duration) []models.CollisionEvent {
+	var events []models.CollisionEvent
+	seconds := dt.Seconds()
+	damping := math.Pow(cfg.Friction, seconds*frictionRate)
+	radius := cfg.PlayerRadius
+	minDistance := cfg.MinDistance()
+
+	// 1. 속도 적용 및 마찰
+	for _, p := range ordered {
+		p.X += p.Vx * seconds
+		p.Y += p.Vy * seconds
+		p.Vx *= damping
+		p.Vy *= damping
+		// 2. 경계 처리
+		if p.X < radius {
+			p.X = radius
+			p.Vx *= -cfg.Restitution
+		}
+		if p.X > cfg.Width-radius {
+			p.X = cfg.Width - radius
+			p.Vx *= -cfg.Restitution
+		}
+		if p.Y < radius {
+			p.Y = radius
+			p.Vy *= -cfg.Restitution
+		}
+		if p.Y > cfg.Height-radius {
+			p.Y = cfg.Height - radius
+			p.Vy *= -cfg.Restitution
+		}
+		// 2-1. 장애물: 벽과 같은 반동, 밀려난 뒤에도 아레나 안에 남도록
+		if len(cfg.Map.Obstacles) > 0 {
+			collideObstacles(cfg, p)
+			p.X, p.Y = cfg.Clamp(p.X, p.Y)
+		}
+	}
+
+	// 3. 플레이어 간 충돌(탄성): 격자에서 이웃한 플레이어끼리만 검사
+	grid.build(ordered)
+	for i, a := range ordered {
+		for _, j := range grid.neighbours(int32(i)) {
+			b := ordered[j]
+			dx := b.X - a.X
+			dy := b.Y - a.Y
+			dist := math.Sqrt(dx*dx + dy*dy)
+			if dist < minDistance && dist > 0 {
+				overlap := minDistance - dist
+				// 각자 반씩 밀어내기
+				pushX := (dx / dist) * (overlap / 2)
+				pushY := (dy / dist) * (overlap / 2)
+				a.X -= pushX
+				a.Y -= pushY
+				b.X += pushX
+				b.Y += pushY
+				// 탄성 충돌(속도 교환)
+				nx, ny := dx/dist, dy/dist
+				va := a.Vx*nx + a.Vy*ny
+				vb := b.Vx*nx + b.Vy*ny
+				a.Vx += (vb - va) * nx
+				a.Vy += (vb - va) * ny
+				b.Vx += (va - vb) * nx
+				b.Vy += (va - vb) * ny
+
+				// 접촉점은 밀어낸 뒤 두 원이 맞닿는 점, 충격량은 법선 방향 상대 속도
+				events = append(events, models.CollisionEvent{
+					A:       a.ID,
+					B:       b.ID,
+					X:       a.X + nx*radius,
+					Y:       a.Y + ny*radius,
+					NX:      nx,
+					NY:      ny,
+					Impulse: math.Abs(va - vb),
+				})
+			}
+		}
+	}
+	return events
+}
+
+// sortedPlayers returns the players ordered by ID
+func sortedPlayers(players map[string]*models.Player) []*models.Player {
+	ordered := make([]*models.Player, 0, len(players))
+	for _, p := range players {
+		ordered = append(ordered, p)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].ID < ordered[j].ID
+	})
+	return ordered
+}
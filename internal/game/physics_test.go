@@ -0,0 +1,169 @@
+package game
+
+import (
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// scriptedInputs returns the inputs each of n players sends on tick t: keys,
+// velocities, and now and then a late input with an older seq
+func scriptedInputs(n, t int) map[string][]models.PlayerInput {
+	inputs := make(map[string][]models.PlayerInput)
+	for i := range n {
+		id := fmt.Sprintf("p%02d", i)
+		seq := uint32(t*2 + 1)
+		switch {
+		case (t+i)%7 == 0:
+			inputs[id] = append(inputs[id], models.PlayerInput{Seq: seq, Key: string("wasd"[(t+i)%4])})
+		case (t+i)%11 == 0:
+			inputs[id] = append(inputs[id], models.PlayerInput{Seq: seq, HasVelocity: true, Vx: float64(i*40 - 200), Vy: float64(t%5*60 - 120)})
+		}
+		if (t+i)%13 == 0 {
+			// 늦게 도착한 예전 입력: 적용은 되지만 lastInputSeq를 되돌리지 않음
+			inputs[id] = append(inputs[id], models.PlayerInput{Seq: seq / 2, Key: "d"})
+		}
+	}
+	return inputs
+}
+
+// scatteredPlayers returns n players at positions drawn from seed
+func scatteredPlayers(cfg models.WorldConfig, n int, seed uint64) []*models.Player {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	players := make([]*models.Player, n)
+	for i := range players {
+		x, y := cfg.Clamp(rng.Float64()*cfg.Width, rng.Float64()*cfg.Height)
+		players[i] = &models.Player{ID: fmt.Sprintf("p%02d", i), X: x, Y: y}
+	}
+	return players
+}
+
+// states returns the players' states without their join time and number,
+// which come from the wall clock and the join order
+func states(players map[string]*models.Player) map[string]models.PlayerState {
+	out := make(map[string]models.PlayerState, len(players))
+	for id, p := range players {
+		st := p.State()
+		st.JoinedAt, st.PlayerNum = time.Time{}, 0
+		out[id] = st
+	}
+	return out
+}
+
+// 작은 아레나에 많이 넣어 충돌이 자주 일어나게 함
+func crowdedWorld() models.WorldConfig {
+	cfg := models.DefaultWorldConfig()
+	cfg.Width, cfg.Height = 300, 300
+	return cfg
+}
+
+const (
+	determinismPlayers = 20
+	determinismTicks   = 600
+)
+
+func TestGamesWithSameInputsAgree(t *testing.T) {
+	cfg := crowdedWorld()
+	games := make([]*Game, 2)
+	for i := range games {
+		games[i] = NewGame(cfg)
+		players := scatteredPlayers(cfg, determinismPlayers, 1)
+		if i == 1 {
+			// 입장 순서가 달라도 결과는 같아야 함
+			for l, r := 0, len(players)-1; l < r; l, r = l+1, r-1 {
+				players[l], players[r] = players[r], players[l]
+			}
+		}
+		for _, p := range players {
+			if err := games[i].AddPlayer(p); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	collisions := 0
+	for tick := range determinismTicks {
+		var events [2][]models.CollisionEvent
+		for i, g := range games {
+			for id, ins := range scriptedInputs(determinismPlayers, tick) {
+				for _, in := range ins {
+					if err := g.QueueInput(id, in); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			events[i] = g.Tick(TickInterval)
+		}
+		collisions += len(events[0])
+		if fmt.Sprint(events[0]) != fmt.Sprint(events[1]) {
+			t.Fatalf("tick %d: collisions %v and %v", tick, events[0], events[1])
+		}
+		if a, b := states(games[0].GetAllPlayers()), states(games[1].GetAllPlayers()); !maps.Equal(a, b) {
+			t.Fatalf("tick %d: games diverged\n%v\n%v", tick, a, b)
+		}
+	}
+	if collisions == 0 {
+		t.Error("no collisions happened, so they weren't compared")
+	}
+}
+
+func TestStepReplaysGame(t *testing.T) {
+	cfg := crowdedWorld()
+	g := NewGame(cfg)
+	replayed := make(map[string]*models.Player)
+	for _, p := range scatteredPlayers(cfg, determinismPlayers, 2) {
+		if err := g.AddPlayer(p); err != nil {
+			t.Fatal(err)
+		}
+		cp := *p
+		replayed[p.ID] = &cp
+	}
+
+	// 클라이언트처럼 같은 입력을 ApplyPlayerInput과 Step으로 재생
+	for tick := range determinismTicks {
+		inputs := scriptedInputs(determinismPlayers, tick)
+		for id, ins := range inputs {
+			for _, in := range ins {
+				if err := g.QueueInput(id, in); err != nil {
+					t.Fatal(err)
+				}
+				ApplyPlayerInput(cfg, replayed[id], in)
+			}
+		}
+		g.Tick(TickInterval)
+		Step(cfg, replayed, TickInterval)
+
+		if a, b := states(g.GetAllPlayers()), states(replayed); !maps.Equal(a, b) {
+			t.Fatalf("tick %d: replay diverged from the game\n%v\n%v", tick, a, b)
+		}
+	}
+}
+
+func TestApplyPlayerInputSeq(t *testing.T) {
+	cfg := models.DefaultWorldConfig()
+	p := &models.Player{ID: "a"}
+	steps := []struct {
+		in      models.PlayerInput
+		wantSeq uint32
+	}{
+		{models.PlayerInput{Seq: 3, Key: "d"}, 3},
+		{models.PlayerInput{Seq: 2, Key: "d"}, 3}, // 늦게 온 입력도 적용하지만 번호는 그대로
+		{models.PlayerInput{Seq: 3, Key: "d"}, 3},
+		{models.PlayerInput{Key: "d"}, 3}, // 번호 없는 입력
+		{models.PlayerInput{Seq: 9, HasVelocity: true}, 9},
+	}
+	for i, s := range steps {
+		vx := p.Vx
+		ApplyPlayerInput(cfg, p, s.in)
+		if p.LastInputSeq != s.wantSeq {
+			t.Errorf("step %d: last input seq %d, want %d", i, p.LastInputSeq, s.wantSeq)
+		}
+		if s.in.Key != "" && p.Vx == vx {
+			t.Errorf("step %d: input with seq %d was not applied", i, s.in.Seq)
+		}
+	}
+}
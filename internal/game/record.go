@@ -0,0 +1,236 @@
+package game
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// Recorder receives every change made to a game between ticks while it is
+// being recorded (see StartRecording). Game calls it with State.Mu held, so
+// it must be quick and must not call back into Game.
+type Recorder interface {
+	Record(ev models.ReplayEvent)
+}
+
+// 녹화 중 상태 해시(check 이벤트)를 남기는 간격 (tick)
+const checkInterval = TickRate
+
+// StartRecording sends every later change to rec and returns the state the
+// recording starts from. The game mode is reseeded so its random picks can
+// be replayed; inputs already queued for the next tick are sent to rec
+// first. A recording already running is replaced.
+func (g *Game) StartRecording(rec Recorder) models.WorldSnapshot {
+	g.State.Mu.Lock()
+	defer g.State.Mu.Unlock()
+
+	seed := rand.Int63()
+	if g.round != nil {
+		if m, ok := g.round.mode.(replayable); ok {
+			m.seed(seed)
+		}
+	}
+	snap := g.snapshot(seed)
+
+	g.recorder = rec
+	for _, p := range g.order {
+		for _, in := range g.inputs[p.ID] {
+			g.record(inputEvent(p.ID, in))
+		}
+	}
+	return snap
+}
+
+// StopRecording ends the recording with an end event
+func (g *Game) StopRecording() {
+	g.State.Mu.Lock()
+	defer g.State.Mu.Unlock()
+	if g.recorder == nil {
+		return
+	}
+	g.record(models.ReplayEvent{Kind: models.ReplayEnd})
+	g.recorder = nil
+}
+
+// record passes ev, stamped with the tick it belongs to, to the recorder.
+// The caller must hold State.Mu.
+func (g *Game) record(ev models.ReplayEvent) {
+	if g.recorder == nil {
+		return
+	}
+	ev.Tick = g.tick + 1
+	g.recorder.Record(ev)
+}
+
+func inputEvent(playerID string, in models.PlayerInput) models.ReplayEvent {
+	return models.ReplayEvent{
+		Kind:        models.ReplayInput,
+		Player:      playerID,
+		Seq:         in.Seq,
+		Key:         in.Key,
+		HasVelocity: in.HasVelocity,
+		Vx:          in.Vx,
+		Vy:          in.Vy,
+	}
+}
+
+// snapshot captures the simulation state. The caller must hold State.Mu.
+func (g *Game) snapshot(seed int64) models.WorldSnapshot {
+	players := g.copyPlayers()
+	snap := models.WorldSnapshot{
+		Tick:        g.tick,
+		PlayerCount: g.State.PlayerCount,
+		Players:     make([]models.Player, 0, len(g.order)),
+		Seed:        seed,
+	}
+	for _, p := range g.order {
+		snap.Players = append(snap.Players, *players[p.ID])
+	}
+	if g.round != nil {
+		snap.Round = &models.RoundSnapshot{
+			Phase:   g.round.phase,
+			Number:  g.round.number,
+			Left:    g.round.left,
+			Results: g.round.results,
+		}
+		if m, ok := g.round.mode.(replayable); ok {
+			// 모드 상태는 단순한 값뿐이라 인코딩에 실패하지 않음
+			snap.Round.Mode, _ = m.saveState()
+		}
+	}
+	return snap
+}
+
+// Restore replaces the game's whole state with snap, as if it had been
+// running up to snap.Tick. The game must have been created with the world
+// config the snapshot was taken in.
+func (g *Game) Restore(snap models.WorldSnapshot) error {
+	g.State.Mu.Lock()
+	defer g.State.Mu.Unlock()
+
+	var r *round
+	if snap.Round != nil {
+		if g.round == nil {
+			return fmt.Errorf("snapshot has a round but the world runs the sandbox")
+		}
+		mode, err := NewMode(g.round.mode.Name())
+		if err != nil {
+			return err
+		}
+		r = newRound(g.cfg.Round, mode)
+		r.phase, r.number, r.left, r.results = snap.Round.Phase, snap.Round.Number, snap.Round.Left, snap.Round.Results
+		if m, ok := mode.(replayable); ok {
+			if len(snap.Round.Mode) > 0 {
+				if err := m.loadState(snap.Round.Mode); err != nil {
+					return fmt.Errorf("restoring %s mode: %w", mode.Name(), err)
+				}
+			}
+			m.seed(snap.Seed)
+		}
+	}
+
+	clear(g.State.Players)
+	clear(g.inputs)
+	g.order = g.order[:0]
+	for i := range snap.Players {
+		p := snap.Players[i]
+		g.State.Players[p.ID] = &p
+		g.order = append(g.order, &p)
+	}
+	slices.SortFunc(g.order, func(a, b *models.Player) int { return comparePlayerID(a, b.ID) })
+
+	g.State.PlayerCount = snap.PlayerCount
+	g.tick = snap.Tick
+	g.replaying = true
+	if snap.Round != nil {
+		g.round = r
+	}
+	return nil
+}
+
+// Apply makes the change a recorded event describes, the way the call that
+// recorded it did. Check and end events change nothing.
+func (g *Game) Apply(ev models.ReplayEvent) {
+	switch ev.Kind {
+	case models.ReplayInput:
+		g.QueueInput(ev.Player, ev.Input())
+	case models.ReplayJoin:
+		if ev.Join != nil {
+			p := *ev.Join
+			g.State.Mu.Lock()
+			g.addPlayer(&p, p.JoinedAt)
+			g.State.Mu.Unlock()
+		}
+	case models.ReplayLeave:
+		g.RemovePlayer(ev.Player)
+	case models.ReplayAway:
+		g.SetAway(ev.Player, ev.Away)
+	case models.ReplayTeleport:
+		// 녹화된 것은 충돌 처리까지 끝난 최종 위치
+		g.State.Mu.Lock()
+		if p, ok := g.State.Players[ev.Player]; ok {
+			p.X, p.Y = ev.X, ev.Y
+		}
+		g.State.Mu.Unlock()
+	}
+}
+
+// Checksum hashes the state the physics works on (positions, velocities and
+// the mode's It and Score), so a replay can tell whether re-simulating
+// reached the same world as the recording
+func (g *Game) Checksum() uint64 {
+	g.State.Mu.RLock()
+	defer g.State.Mu.RUnlock()
+	return g.checksum()
+}
+
+// checksum is Checksum for callers holding State.Mu
+func (g *Game) checksum() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	put := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	for _, p := range g.order {
+		h.Write([]byte(p.ID))
+		put(math.Float64bits(p.X))
+		put(math.Float64bits(p.Y))
+		put(math.Float64bits(p.Vx))
+		put(math.Float64bits(p.Vy))
+		put(uint64(p.Score))
+		if p.It {
+			put(1)
+		} else {
+			put(0)
+		}
+	}
+	return h.Sum64()
+}
+
+// recordCheck leaves a state hash in the recording every checkInterval
+// ticks. The caller must hold State.Mu, right after a tick.
+func (g *Game) recordCheck() {
+	if g.recorder == nil || g.tick%checkInterval != 0 {
+		return
+	}
+	// 이 tick이 끝난 상태이므로 다음 tick 앞에 둠
+	g.record(models.ReplayEvent{Kind: models.ReplayCheck, Hash: g.checksum()})
+}
+
+// joinEvent records a player that joined. The caller must hold State.Mu.
+func (g *Game) joinEvent(p *models.Player) {
+	if g.recorder == nil {
+		return
+	}
+	joined := *p
+	joined.Conn = nil
+	joined.LastSeen = time.Time{}
+	g.record(models.ReplayEvent{Kind: models.ReplayJoin, Player: p.ID, Join: &joined})
+}
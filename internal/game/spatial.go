@@ -0,0 +1,110 @@
+package game
+
+import (
+	"math"
+	"slices"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// spatialGrid is a uniform grid over the world used as the collision
+// broadphase. Players are bucketed by the cell their center is in; with cells
+// at least minDistance wide, two overlapping players are always in the same
+// or neighbouring cells, so each player only checks the 3x3 cells around it
+// instead of every other player.
+//
+// The grid is rebuilt from scratch (counting sort, no allocations once warm)
+// whenever it is used, which is cheaper than keeping it in sync with moves.
+type spatialGrid struct {
+	cellSize   float64
+	cols, rows int
+
+	start  []int32 // cell c holds items[start[c]:start[c+1]]
+	items  []int32 // 플레이어 인덱스, 셀 안에서는 오름차순
+	cellOf []int32 // 플레이어 인덱스 -> 셀
+	fill   []int32 // build용 재사용 버퍼
+	near   []int32 // 후보 수집용 재사용 버퍼
+}
+
+// newSpatialGrid creates a grid covering a width x height world
+func newSpatialGrid(width, height, cellSize float64) *spatialGrid {
+	cols := max(1, int(math.Ceil(width/cellSize)))
+	rows := max(1, int(math.Ceil(height/cellSize)))
+	return &spatialGrid{
+		cellSize: cellSize,
+		cols:     cols,
+		rows:     rows,
+		start:    make([]int32, cols*rows+1),
+	}
+}
+
+// cell returns the cell coordinates of a point, clamped to the grid
+func (g *spatialGrid) cell(x, y float64) (int, int) {
+	cx := min(max(int(x/g.cellSize), 0), g.cols-1)
+	cy := min(max(int(y/g.cellSize), 0), g.rows-1)
+	return cx, cy
+}
+
+// build buckets players by their current position
+func (g *spatialGrid) build(players []*models.Player) {
+	n := len(players)
+	g.items = slices.Grow(g.items[:0], n)[:n]
+	g.cellOf = slices.Grow(g.cellOf[:0], n)[:n]
+	clear(g.start)
+
+	// 셀별 개수 -> 누적합 -> 채우기 (입력 순서가 유지되므로 셀 안은 오름차순)
+	for i, p := range players {
+		cx, cy := g.cell(p.X, p.Y)
+		c := int32(cy*g.cols + cx)
+		g.cellOf[i] = c
+		g.start[c+1]++
+	}
+	for c := 1; c < len(g.start); c++ {
+		g.start[c] += g.start[c-1]
+	}
+	g.fill = append(g.fill[:0], g.start[:len(g.start)-1]...)
+	for i, c := range g.cellOf {
+		g.items[g.fill[c]] = int32(i)
+		g.fill[c]++
+	}
+}
+
+// neighbours returns, in ascending order, the indexes of players in the 3x3
+// cells around player i's cell that come after i. The slice is reused by the
+// next call.
+func (g *spatialGrid) neighbours(i int32) []int32 {
+	c := int(g.cellOf[i])
+	cx, cy := c%g.cols, c/g.cols
+	g.near = g.near[:0]
+	for y := max(cy-1, 0); y <= min(cy+1, g.rows-1); y++ {
+		for x := max(cx-1, 0); x <= min(cx+1, g.cols-1); x++ {
+			cell := y*g.cols + x
+			for _, j := range g.items[g.start[cell]:g.start[cell+1]] {
+				if j > i {
+					g.near = append(g.near, j)
+				}
+			}
+		}
+	}
+	slices.Sort(g.near)
+	return g.near
+}
+
+// occupied reports whether a player centered at (x, y) would be closer than
+// minDistance to any of the players the grid was built from
+func (g *spatialGrid) occupied(players []*models.Player, x, y, minDistance float64) bool {
+	cx, cy := g.cell(x, y)
+	for yy := max(cy-1, 0); yy <= min(cy+1, g.rows-1); yy++ {
+		for xx := max(cx-1, 0); xx <= min(cx+1, g.cols-1); xx++ {
+			cell := yy*g.cols + xx
+			for _, j := range g.items[g.start[cell]:g.start[cell+1]] {
+				dx := x - players[j].X
+				dy := y - players[j].Y
+				if dx*dx+dy*dy < minDistance*minDistance {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
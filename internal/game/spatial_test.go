@@ -0,0 +1,99 @@
+package game
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// randomPlayers places n players in a width x height world. Some sit exactly
+// on cell borders or a hair either side of them, and a few are outside the
+// world, so clamping to the edge cells is exercised too.
+func randomPlayers(rng *rand.Rand, n int, width, height, cellSize float64) []*models.Player {
+	coord := func(size float64) float64 {
+		switch rng.IntN(5) {
+		case 0: // 셀 경계 위
+			return float64(rng.IntN(int(size/cellSize)+1)) * cellSize
+		case 1: // 경계 바로 양옆
+			return float64(rng.IntN(int(size/cellSize)+1))*cellSize + (rng.Float64()*2-1)*1e-9
+		case 2: // 월드 밖
+			return rng.Float64()*(size+4*cellSize) - 2*cellSize
+		default:
+			return rng.Float64() * size
+		}
+	}
+	players := make([]*models.Player, n)
+	for i := range players {
+		players[i] = &models.Player{X: coord(width), Y: coord(height)}
+	}
+	return players
+}
+
+func overlapping(a, b *models.Player, minDistance float64) bool {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return dx*dx+dy*dy < minDistance*minDistance
+}
+
+func TestSpatialGridNeighboursMatchBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 9))
+	const width, height, cellSize = 400.0, 300.0, 30.0
+	grid := newSpatialGrid(width, height, cellSize)
+
+	for round := 0; round < 50; round++ {
+		players := randomPlayers(rng, 20+rng.IntN(300), width, height, cellSize)
+		grid.build(players)
+
+		for i := range players {
+			near := slices.Clone(grid.neighbours(int32(i)))
+			if !slices.IsSorted(near) {
+				t.Fatalf("round %d: neighbours(%d) not sorted: %v", round, i, near)
+			}
+
+			// 3x3 칸 안의 i 이후 플레이어를 전부 비교해서 구한 답
+			var want []int32
+			cx, cy := grid.cell(players[i].X, players[i].Y)
+			for j := i + 1; j < len(players); j++ {
+				jx, jy := grid.cell(players[j].X, players[j].Y)
+				if abs(jx-cx) <= 1 && abs(jy-cy) <= 1 {
+					want = append(want, int32(j))
+				}
+				if overlapping(players[i], players[j], cellSize) && !slices.Contains(near, int32(j)) {
+					t.Fatalf("round %d: overlapping players %d (%g, %g) and %d (%g, %g) are not neighbours",
+						round, i, players[i].X, players[i].Y, j, players[j].X, players[j].Y)
+				}
+			}
+			if !slices.Equal(near, want) {
+				t.Fatalf("round %d: neighbours(%d) = %v, want %v", round, i, near, want)
+			}
+		}
+	}
+}
+
+func TestSpatialGridOccupiedMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 5))
+	const width, height, cellSize = 400.0, 300.0, 30.0
+	grid := newSpatialGrid(width, height, cellSize)
+
+	for round := 0; round < 50; round++ {
+		players := randomPlayers(rng, rng.IntN(200), width, height, cellSize)
+		grid.build(players)
+
+		for _, spot := range randomPlayers(rng, 100, width, height, cellSize) {
+			want := slices.ContainsFunc(players, func(p *models.Player) bool {
+				return overlapping(spot, p, cellSize)
+			})
+			if got := grid.occupied(players, spot.X, spot.Y, cellSize); got != want {
+				t.Fatalf("round %d: occupied(%g, %g) = %v, want %v", round, spot.X, spot.Y, got, want)
+			}
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
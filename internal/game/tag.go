@@ -0,0 +1,156 @@
+package game
+
+import (
+	"encoding/json"
+	"math/rand"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 술래가 바뀐 직후 바로 되잡기(tag-back)를 막는 시간
+const tagBackDelay = time.Second
+
+// TagMode is classic tag: one player is "it" and passes it on by touching
+// another player. Everyone else scores a point for every second they spend
+// not being it, so the winner is whoever was it the least.
+type TagMode struct {
+	playing  bool
+	elapsed  time.Duration            // 라운드 경과 시간
+	safe     map[string]time.Duration // 술래가 아니었던 시간
+	tagger   string                   // 마지막으로 술래를 넘긴 플레이어
+	taggedAt time.Duration
+	rng      *rand.Rand // 술래 뽑기 (리플레이에서 같은 결과가 나오도록 시드 고정)
+}
+
+// NewTagMode creates a tag mode
+func NewTagMode() *TagMode {
+	return &TagMode{
+		safe: make(map[string]time.Duration),
+		rng:  rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+// Name implements GameMode
+func (m *TagMode) Name() string {
+	return "tag"
+}
+
+// OnJoin implements GameMode; late joiners start with no score
+func (m *TagMode) OnJoin(p *models.Player, players []*models.Player) {
+	p.It = false
+	p.Score = 0
+}
+
+// OnLeave implements GameMode; if "it" left, someone else becomes it
+func (m *TagMode) OnLeave(p *models.Player, players []*models.Player) {
+	delete(m.safe, p.ID)
+	if m.playing && p.It {
+		m.pickIt(players)
+	}
+}
+
+// OnPhase implements GameMode
+func (m *TagMode) OnPhase(phase models.RoundPhase, players []*models.Player) {
+	m.playing = phase == models.PhasePlaying
+	if !m.playing {
+		return
+	}
+	m.elapsed = 0
+	m.tagger = ""
+	clear(m.safe)
+	for _, p := range players {
+		p.It = false
+		p.Score = 0
+	}
+	m.pickIt(players)
+}
+
+// OnTick implements GameMode
+func (m *TagMode) OnTick(players []*models.Player, dt time.Duration) {
+	m.elapsed += dt
+
+	hasIt := false
+	for _, p := range players {
+		// 연결이 끊긴 술래는 놓아줌
+		if p.It && p.Away {
+			p.It = false
+		}
+		hasIt = hasIt || p.It
+	}
+	if !hasIt {
+		m.pickIt(players)
+	}
+
+	for _, p := range players {
+		if p.It || p.Away {
+			continue
+		}
+		m.safe[p.ID] += dt
+		p.Score = int(m.safe[p.ID] / time.Second)
+	}
+}
+
+// OnCollision implements GameMode: it tags whoever it touches
+func (m *TagMode) OnCollision(a, b *models.Player) {
+	it, other := a, b
+	if b.It {
+		it, other = b, a
+	}
+	if !it.It || other.It || other.Away {
+		return
+	}
+	// 방금 술래를 넘겨준 사람은 잠깐 다시 잡을 수 없음
+	if other.ID == m.tagger && m.elapsed-m.taggedAt < tagBackDelay {
+		return
+	}
+	it.It = false
+	other.It = true
+	m.tagger = it.ID
+	m.taggedAt = m.elapsed
+}
+
+// pickIt makes a random connected player it
+func (m *TagMode) pickIt(players []*models.Player) {
+	var candidates []*models.Player
+	for _, p := range players {
+		if !p.Away {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	candidates[m.rng.Intn(len(candidates))].It = true
+	m.tagger = ""
+}
+
+// tagState is TagMode's state as saved in a replay
+type tagState struct {
+	Playing  bool                     `json:"playing"`
+	Elapsed  time.Duration            `json:"elapsed"`
+	Safe     map[string]time.Duration `json:"safe"`
+	Tagger   string                   `json:"tagger"`
+	TaggedAt time.Duration            `json:"taggedAt"`
+}
+
+func (m *TagMode) seed(seed int64) {
+	m.rng = rand.New(rand.NewSource(seed))
+}
+
+func (m *TagMode) saveState() ([]byte, error) {
+	return json.Marshal(tagState{m.playing, m.elapsed, m.safe, m.tagger, m.taggedAt})
+}
+
+func (m *TagMode) loadState(data []byte) error {
+	var st tagState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return err
+	}
+	m.playing, m.elapsed, m.tagger, m.taggedAt = st.Playing, st.Elapsed, st.Tagger, st.TaggedAt
+	m.safe = st.Safe
+	if m.safe == nil {
+		m.safe = make(map[string]time.Duration)
+	}
+	return nil
+}
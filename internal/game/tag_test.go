@@ -0,0 +1,134 @@
+package game
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// playingTag returns a tag mode in the playing phase with a it and b, c not
+func playingTag() (*TagMode, []*models.Player) {
+	players := []*models.Player{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+	m := NewTagMode()
+	m.OnPhase(models.PhasePlaying, players)
+	for _, p := range players {
+		p.It = p.ID == "a"
+	}
+	return m, players
+}
+
+// it returns the ID of every player who is it
+func it(players []*models.Player) []string {
+	var ids []string
+	for _, p := range players {
+		if p.It {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
+
+func TestTagCollisionPassesIt(t *testing.T) {
+	for _, order := range []string{"it first", "it second"} {
+		t.Run(order, func(t *testing.T) {
+			m, players := playingTag()
+			a, b := players[0], players[1]
+			if order == "it first" {
+				m.OnCollision(a, b)
+			} else {
+				m.OnCollision(b, a)
+			}
+			if got := it(players); len(got) != 1 || got[0] != "b" {
+				t.Errorf("it is %v, want [b]", got)
+			}
+		})
+	}
+}
+
+func TestTagIgnoresOtherContacts(t *testing.T) {
+	m, players := playingTag()
+	b, c := players[1], players[2]
+	m.OnCollision(b, c)
+	c.Away = true
+	m.OnCollision(players[0], c)
+	if got := it(players); len(got) != 1 || got[0] != "a" {
+		t.Errorf("it is %v, want [a]: neither a contact without it nor an away player passes it", got)
+	}
+}
+
+func TestTagBackCooldown(t *testing.T) {
+	m, players := playingTag()
+	a, b, c := players[0], players[1], players[2]
+	m.OnCollision(a, b)
+
+	// b가 곧바로 a를 되잡을 수는 없지만 다른 사람은 잡을 수 있음
+	m.OnTick(players, tagBackDelay/2)
+	m.OnCollision(b, a)
+	if got := it(players); len(got) != 1 || got[0] != "b" {
+		t.Fatalf("it is %v after a tag-back within %v, want [b]", got, tagBackDelay)
+	}
+
+	m.OnTick(players, tagBackDelay/2)
+	m.OnCollision(b, a)
+	if got := it(players); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("it is %v after the cooldown, want [a]", got)
+	}
+
+	// a가 넘겨받았으니 이제 b를 잡을 수 없고 c는 잡을 수 있음
+	m.OnCollision(a, b)
+	m.OnCollision(a, c)
+	if got := it(players); len(got) != 1 || got[0] != "c" {
+		t.Errorf("it is %v, want [c]", got)
+	}
+}
+
+func TestTagScoresTimeNotIt(t *testing.T) {
+	m, players := playingTag()
+	m.OnTick(players, 2500*time.Millisecond)
+	for _, p := range players {
+		want := 2
+		if p.It {
+			want = 0
+		}
+		if p.Score != want {
+			t.Errorf("player %s (it=%v) scored %d, want %d", p.ID, p.It, p.Score, want)
+		}
+	}
+}
+
+func TestTagPicksNewItWhenItLeaves(t *testing.T) {
+	m, players := playingTag()
+	a := players[0]
+	rest := players[1:]
+	m.OnLeave(a, rest)
+	if got := it(rest); len(got) != 1 {
+		t.Errorf("it is %v after it left, want one of b and c", got)
+	}
+}
+
+func TestTagThroughPhysics(t *testing.T) {
+	g := newRoundGame(t, "a", "b")
+	runRound(g, 1+4) // playing
+	players := g.GetAllPlayers()
+	itID, otherID := "a", "b"
+	if players["b"].It {
+		itID, otherID = "b", "a"
+	}
+
+	// 술래 쪽으로 달려와 부딪히게 함
+	g.State.Mu.Lock()
+	minDistance := g.cfg.MinDistance()
+	g.State.Players[itID].X, g.State.Players[itID].Y = 400, 300
+	other := g.State.Players[otherID]
+	other.X, other.Y, other.Vx = 400-minDistance-1, 300, 300
+	g.State.Mu.Unlock()
+
+	for range 4 {
+		g.Tick(TickInterval)
+	}
+	players = g.GetAllPlayers()
+	if players[itID].It || !players[otherID].It {
+		t.Errorf("after the collision %s it=%v, %s it=%v, want it passed on", itID, players[itID].It, otherID, players[otherID].It)
+	}
+}
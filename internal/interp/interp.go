@@ -0,0 +1,162 @@
+// Package interp buffers game_state snapshots and answers "where was every
+// player at time t", interpolating between the two snapshots around t and
+// extrapolating a little past the newest one. Headless clients, load tools
+// and tests use it the same way a renderer would.
+package interp
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// DefaultMaxExtrapolation caps how far past the newest snapshot positions
+// are extrapolated from velocity
+const DefaultMaxExtrapolation = 100 * time.Millisecond
+
+// Snapshot is one full world state as received from the server
+type Snapshot struct {
+	Tick         uint64
+	ServerTime   time.Time
+	TickInterval time.Duration
+	Players      map[string]models.PlayerState
+}
+
+// FromPayload builds a Snapshot from a game_state envelope and the full player
+// map the client reconstructed from it (deltas already applied)
+func FromPayload(payload models.GameStatePayload, players map[string]models.PlayerState) Snapshot {
+	return Snapshot{
+		Tick:         payload.Tick,
+		ServerTime:   payload.Time(),
+		TickInterval: payload.Interval(),
+		Players:      players,
+	}
+}
+
+// Position is an interpolated player position
+type Position struct {
+	X float64
+	Y float64
+}
+
+// Buffer keeps the most recent snapshots ordered by tick
+type Buffer struct {
+	size             int
+	snapshots        []Snapshot
+	MaxExtrapolation time.Duration
+}
+
+// NewBuffer creates a buffer holding at most size snapshots
+func NewBuffer(size int) *Buffer {
+	if size < 2 {
+		size = 2
+	}
+	return &Buffer{
+		size:             size,
+		snapshots:        make([]Snapshot, 0, size),
+		MaxExtrapolation: DefaultMaxExtrapolation,
+	}
+}
+
+// Add inserts a snapshot. Duplicates and snapshots older than the whole
+// buffer are ignored, so out-of-order delivery is harmless.
+func (b *Buffer) Add(s Snapshot) {
+	i := sort.Search(len(b.snapshots), func(i int) bool {
+		return b.snapshots[i].Tick >= s.Tick
+	})
+	if i < len(b.snapshots) && b.snapshots[i].Tick == s.Tick {
+		return
+	}
+	if i == 0 && len(b.snapshots) == b.size {
+		return
+	}
+	b.snapshots = append(b.snapshots, Snapshot{})
+	copy(b.snapshots[i+1:], b.snapshots[i:])
+	b.snapshots[i] = s
+	if len(b.snapshots) > b.size {
+		b.snapshots = b.snapshots[1:]
+	}
+}
+
+// Len returns the number of buffered snapshots
+func (b *Buffer) Len() int {
+	return len(b.snapshots)
+}
+
+// Latest returns the newest snapshot
+func (b *Buffer) Latest() (Snapshot, bool) {
+	if len(b.snapshots) == 0 {
+		return Snapshot{}, false
+	}
+	return b.snapshots[len(b.snapshots)-1], true
+}
+
+// At returns every player's position at render time t (in server time).
+// Clients usually render slightly in the past, e.g. At(serverNow - 100ms),
+// so there is a snapshot on both sides of t.
+func (b *Buffer) At(t time.Time) map[string]Position {
+	n := len(b.snapshots)
+	if n == 0 {
+		return map[string]Position{}
+	}
+
+	// t 이전: 가장 오래된 스냅샷 그대로
+	first := b.snapshots[0]
+	if !t.After(first.ServerTime) {
+		return positions(first)
+	}
+
+	// t 이후: 최신 스냅샷에서 속도로 외삽
+	last := b.snapshots[n-1]
+	if !t.Before(last.ServerTime) {
+		return b.extrapolate(last, t.Sub(last.ServerTime))
+	}
+
+	// t를 감싸는 두 스냅샷 사이를 선형 보간
+	j := sort.Search(n, func(i int) bool {
+		return b.snapshots[i].ServerTime.After(t)
+	})
+	from, to := b.snapshots[j-1], b.snapshots[j]
+	span := to.ServerTime.Sub(from.ServerTime)
+	alpha := 0.0
+	if span > 0 {
+		alpha = float64(t.Sub(from.ServerTime)) / float64(span)
+	}
+
+	result := make(map[string]Position, len(to.Players))
+	for id, next := range to.Players {
+		prev, ok := from.Players[id]
+		if !ok {
+			// 새로 나타난 플레이어는 보간 없이 표시
+			result[id] = Position{X: next.X, Y: next.Y}
+			continue
+		}
+		result[id] = Position{
+			X: prev.X + (next.X-prev.X)*alpha,
+			Y: prev.Y + (next.Y-prev.Y)*alpha,
+		}
+	}
+	return result
+}
+
+func (b *Buffer) extrapolate(s Snapshot, ahead time.Duration) map[string]Position {
+	if ahead > b.MaxExtrapolation {
+		ahead = b.MaxExtrapolation
+	}
+	// 속도 단위는 px/s
+	seconds := ahead.Seconds()
+	result := make(map[string]Position, len(s.Players))
+	for id, p := range s.Players {
+		result[id] = Position{X: p.X + p.Vx*seconds, Y: p.Y + p.Vy*seconds}
+	}
+	return result
+}
+
+func positions(s Snapshot) map[string]Position {
+	result := make(map[string]Position, len(s.Players))
+	for id, p := range s.Players {
+		result[id] = Position{X: p.X, Y: p.Y}
+	}
+	return result
+}
@@ -0,0 +1,123 @@
+package interp
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+var t0 = time.UnixMilli(1700000000000)
+
+// snap is the snapshot of tick, taken tick*50ms after t0
+func snap(tick uint64, players ...models.PlayerState) Snapshot {
+	s := Snapshot{
+		Tick:         tick,
+		ServerTime:   t0.Add(time.Duration(tick) * 50 * time.Millisecond),
+		TickInterval: 50 * time.Millisecond,
+		Players:      make(map[string]models.PlayerState, len(players)),
+	}
+	for _, p := range players {
+		s.Players[p.ID] = p
+	}
+	return s
+}
+
+func at(id string, x, y, vx, vy float64) models.PlayerState {
+	return models.PlayerState{ID: id, X: x, Y: y, Vx: vx, Vy: vy}
+}
+
+func ticks(b *Buffer) []uint64 {
+	out := make([]uint64, len(b.snapshots))
+	for i, s := range b.snapshots {
+		out[i] = s.Tick
+	}
+	return out
+}
+
+func TestBufferAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		add  []uint64
+		want []uint64
+	}{
+		{"in order", 4, []uint64{1, 2, 3}, []uint64{1, 2, 3}},
+		{"out of order", 4, []uint64{3, 1, 2}, []uint64{1, 2, 3}},
+		{"duplicate", 4, []uint64{1, 2, 2, 1}, []uint64{1, 2}},
+		{"oldest dropped when full", 3, []uint64{1, 2, 3, 4, 5}, []uint64{3, 4, 5}},
+		{"older than a full buffer", 3, []uint64{4, 5, 6, 2}, []uint64{4, 5, 6}},
+		{"late one inside a full buffer", 3, []uint64{4, 6, 7, 5}, []uint64{5, 6, 7}},
+		{"size below two", 0, []uint64{1, 2, 3}, []uint64{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuffer(tt.size)
+			for _, tick := range tt.add {
+				b.Add(snap(tick))
+			}
+			if got := ticks(b); !slices.Equal(got, tt.want) {
+				t.Errorf("ticks %v, want %v", got, tt.want)
+			}
+			if latest, ok := b.Latest(); !ok || latest.Tick != tt.want[len(tt.want)-1] {
+				t.Errorf("Latest = %d, %v, want %d", latest.Tick, ok, tt.want[len(tt.want)-1])
+			}
+		})
+	}
+}
+
+func TestBufferAt(t *testing.T) {
+	b := NewBuffer(8)
+	b.Add(snap(2, at("a", 100, 100, 0, 0), at("b", 0, 0, 0, 0)))
+	b.Add(snap(4, at("a", 200, 0, 600, -300), at("c", 50, 50, 0, 0)))
+	b.Add(snap(6, at("a", 200, 0, 600, -300)))
+
+	tests := []struct {
+		name  string
+		delay time.Duration // t0부터
+		want  map[string]Position
+	}{
+		{"before the oldest", 0, map[string]Position{"a": {100, 100}, "b": {0, 0}}},
+		{"on the oldest", 100 * time.Millisecond, map[string]Position{"a": {100, 100}, "b": {0, 0}}},
+		// b는 다음 스냅샷에 없으므로 빠지고, c는 처음 나타났으므로 보간 없이
+		{"a quarter between", 125 * time.Millisecond, map[string]Position{"a": {125, 75}, "c": {50, 50}}},
+		{"halfway", 150 * time.Millisecond, map[string]Position{"a": {150, 50}, "c": {50, 50}}},
+		{"on a middle snapshot", 200 * time.Millisecond, map[string]Position{"a": {200, 0}}},
+		{"on the newest", 300 * time.Millisecond, map[string]Position{"a": {200, 0}}},
+		{"extrapolated", 350 * time.Millisecond, map[string]Position{"a": {230, -15}}},
+		{"extrapolation capped", time.Second, map[string]Position{"a": {260, -30}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := b.At(t0.Add(tt.delay))
+			if !maps.EqualFunc(got, tt.want, closeTo) {
+				t.Errorf("At(+%v) = %v, want %v", tt.delay, got, tt.want)
+			}
+		})
+	}
+}
+
+func closeTo(a, b Position) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestBufferAtEmpty(t *testing.T) {
+	b := NewBuffer(4)
+	if got := b.At(t0); len(got) != 0 {
+		t.Errorf("At on an empty buffer = %v, want no players", got)
+	}
+	if _, ok := b.Latest(); ok {
+		t.Error("Latest on an empty buffer reported a snapshot")
+	}
+}
+
+func TestFromPayload(t *testing.T) {
+	players := map[string]models.PlayerState{"a": at("a", 1, 2, 3, 4)}
+	s := FromPayload(models.GameStatePayload{Tick: 30, ServerTime: t0.UnixMilli(), TickInterval: 1000.0 / 60}, players)
+	if s.Tick != 30 || !s.ServerTime.Equal(t0) || s.TickInterval != time.Second/60 || !maps.Equal(s.Players, players) {
+		t.Errorf("got %+v", s)
+	}
+}
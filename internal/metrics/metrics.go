@@ -0,0 +1,156 @@
+// Package metrics holds the Prometheus metrics the server exports on
+// /metrics. They are registered on the default registry, next to the Go
+// runtime and process metrics, and updated directly by the websocket
+// handler, the rooms and the game.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+const namespace = "multiplayer"
+
+var (
+	// Players is the number of players in a world, in any room, including
+	// players waiting for a reconnect but not bots
+	Players = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "players",
+		Help:      "Players in a world, in any room (away players included, bots not).",
+	})
+
+	// Bots is the number of server-side bots in a world, in any room
+	Bots = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "bots",
+		Help:      "Server-side bots in a world, in any room.",
+	})
+
+	// Connections is the number of open websocket connections
+	Connections = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connections",
+		Help:      "Open websocket connections, logged in or not.",
+	})
+
+	// Logins counts players entering a world: "new" logins and "resume"s
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Players that joined a world, by kind (new, resume).",
+	}, []string{"kind"})
+
+	// Disconnects counts closed connections by why they closed
+	Disconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "disconnects_total",
+		Help:      "Closed websocket connections, by reason.",
+	}, []string{"reason"})
+
+	// MessagesReceived counts client messages by type
+	MessagesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_received_total",
+		Help:      "Messages received from clients, by type.",
+	}, []string{"type"})
+
+	// MessagesRejected counts client messages answered with an error, by
+	// error code
+	MessagesRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_rejected_total",
+		Help:      "Client messages the server refused with an error message, by code.",
+	}, []string{"code"})
+
+	// MessagesSent counts messages written to clients by type
+	MessagesSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_sent_total",
+		Help:      "Messages written to clients, by type.",
+	}, []string{"type"})
+
+	// BytesReceived counts the bytes of every client message
+	BytesReceived = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "received_bytes_total",
+		Help:      "Bytes received from clients.",
+	})
+
+	// BytesSent counts the bytes written to clients
+	BytesSent = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sent_bytes_total",
+		Help:      "Bytes written to clients.",
+	})
+
+	// DroppedFrames counts game_state frames dropped from full send queues
+	DroppedFrames = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dropped_frames_total",
+		Help:      "Stale game_state frames dropped because a client's send queue was full.",
+	})
+
+	// TickDuration is how long one physics step (Game.Tick) takes, not
+	// counting the wait for the game lock
+	TickDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tick_duration_seconds",
+		Help:      "Time spent in one physics step.",
+		Buckets:   prometheus.ExponentialBuckets(0.00005, 2, 12), // 50µs ~ 100ms
+	})
+
+	// TickOverruns counts room loop iterations that took longer than one
+	// step, so the loop fell behind
+	TickOverruns = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tick_overruns_total",
+		Help:      "Room loop iterations (steps and sends) that took longer than one step.",
+	})
+
+	// BroadcastDuration is how long a room takes to fan one game_state out
+	// to all of its clients (building, encoding and queueing), not counting
+	// the wait for the room lock
+	BroadcastDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "broadcast_fanout_seconds",
+		Help:      "Time to build, encode and queue one game_state for every client of a room.",
+		Buckets:   prometheus.ExponentialBuckets(0.00005, 2, 12),
+	})
+
+	// MarshalErrors counts messages that could not be encoded, by codec
+	MarshalErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "marshal_errors_total",
+		Help:      "Messages that failed to encode, by codec.",
+	}, []string{"codec"})
+)
+
+// 클라이언트가 보낼 수 있는 타입, 나머지는 "other"로 묶어 라벨 수를 제한
+var clientTypes = map[models.MessageType]bool{
+	models.MessageTypeLogin:     true,
+	models.MessageTypeInput:     true,
+	models.MessageTypeJoinRoom:  true,
+	models.MessageTypeStateAck:  true,
+	models.MessageTypeReconnect: true,
+	models.MessageTypeChat:      true,
+	models.MessageTypeSpectate:  true,
+
+	models.MessageTypeReplayControl: true,
+}
+
+// Received records one client message of type t. Types a client isn't
+// expected to send are counted as "other", undecodable ones as "invalid".
+func Received(t models.MessageType) {
+	label := string(t)
+	if !clientTypes[t] {
+		label = "other"
+	}
+	MessagesReceived.WithLabelValues(label).Inc()
+}
+
+// ReceivedInvalid records a client message that could not be decoded
+func ReceivedInvalid() {
+	MessagesReceived.WithLabelValues("invalid").Inc()
+}
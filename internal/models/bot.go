@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BotConfig is how many server-side bots a room keeps and how they play.
+// Bots fill the world up to Target players and make way for humans.
+type BotConfig struct {
+	Target    int      `json:"target" yaml:"target"`                 // 방의 목표 인원: 사람이 이보다 적으면 봇으로 채움 (0 = 봇 없음)
+	Behaviors []string `json:"behaviors,omitempty" yaml:"behaviors"` // 봇에게 차례로 줄 행동 (wander, chase, flee, path), 비우면 전부
+	ThinkRate float64  `json:"thinkRate" yaml:"thinkRate"`           // 봇마다 초당 입력 수
+	Path      []Point  `json:"path,omitempty" yaml:"path"`           // path 행동의 경유지 (비우면 아레나 안쪽을 한 바퀴)
+}
+
+// DefaultBotConfig returns the bot settings used when nothing is set: no
+// bots, thinking five times a second once there are some
+func DefaultBotConfig() BotConfig {
+	return BotConfig{ThinkRate: 5}
+}
+
+// ThinkInterval returns the time between two inputs of a bot
+func (c BotConfig) ThinkInterval() time.Duration {
+	return time.Duration(float64(time.Second) / c.ThinkRate)
+}
+
+// Validate reports the first setting that can't work in a width x height
+// arena. Behavior names are checked by the game (see game.NewBehavior).
+func (c BotConfig) Validate(width, height float64) error {
+	switch {
+	case c.Target < 0:
+		return errors.New("target must not be negative")
+	case c.ThinkRate <= 0:
+		return errors.New("thinkRate must be positive")
+	}
+	for i, p := range c.Path {
+		if p.X < 0 || p.X > width || p.Y < 0 || p.Y > height {
+			return fmt.Errorf("path point %d (%g, %g) is outside the %gx%g arena", i, p.X, p.Y, width, height)
+		}
+	}
+	return nil
+}
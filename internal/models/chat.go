@@ -0,0 +1,29 @@
+package models
+
+// ChatChannel is where a chat message goes
+type ChatChannel string
+
+const (
+	ChatGlobal ChatChannel = "global" // 모든 방의 모든 플레이어
+	ChatRoom   ChatChannel = "room"   // 같은 방의 플레이어
+	ChatDirect ChatChannel = "dm"     // 한 플레이어에게 (To)
+)
+
+// ChatMessage is a chat line as delivered to clients
+type ChatMessage struct {
+	ID       uint64      `json:"id"` // 서버가 붙이는 증가 번호
+	Channel  ChatChannel `json:"channel"`
+	Room     string      `json:"room,omitempty"` // room 채널의 방
+	From     string      `json:"from"`           // 보낸 플레이어 ID
+	FromName string      `json:"fromName"`
+	To       string      `json:"to,omitempty"` // dm 받는 플레이어 ID
+	Text     string      `json:"text"`
+	SentAt   int64       `json:"sentAt"` // Unix ms
+}
+
+// ChatHistory is the payload of chat_history, sent after login: the recent
+// global and room messages, oldest first
+type ChatHistory struct {
+	Global []ChatMessage `json:"global"`
+	Room   []ChatMessage `json:"room"`
+}
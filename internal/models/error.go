@@ -0,0 +1,59 @@
+package models
+
+// ErrorCode identifies why the server refused a client message. Codes are
+// stable; clients switch on them rather than on the message text.
+type ErrorCode string
+
+const (
+	// The frame isn't a message the codec can read
+	ErrorCodeBadMessage ErrorCode = "bad_message"
+
+	// The message type isn't one a client can send
+	ErrorCodeUnknownType ErrorCode = "unknown_type"
+
+	// The payload is missing a field, has one of the wrong type or out of range
+	ErrorCodeInvalidPayload ErrorCode = "invalid_payload"
+
+	// Input or chat from a connection that hasn't logged in
+	ErrorCodeNotLoggedIn ErrorCode = "not_logged_in"
+
+	// Login or spectate from a connection that already plays
+	ErrorCodeAlreadyLoggedIn ErrorCode = "already_logged_in"
+
+	// The room has maxPlayers players
+	ErrorCodeRoomFull ErrorCode = "room_full"
+
+	// The server is draining and takes no new players
+	ErrorCodeDraining ErrorCode = "draining"
+
+	// The room plays a replay, which can only be watched
+	ErrorCodeReplayRoom ErrorCode = "replay_room"
+
+	// replay_control in a room that doesn't play a replay
+	ErrorCodeNotReplay ErrorCode = "not_replay"
+
+	// The resume token is unknown or expired
+	ErrorCodeInvalidToken ErrorCode = "invalid_token"
+
+	// A dm to a player who isn't online
+	ErrorCodePlayerNotFound ErrorCode = "player_not_found"
+
+	// Chat sent faster than the rate limit
+	ErrorCodeRateLimited ErrorCode = "rate_limited"
+
+	// Chat blocked by the word filter
+	ErrorCodeFiltered ErrorCode = "filtered"
+
+	// The server failed to carry out a valid request
+	ErrorCodeInternal ErrorCode = "internal_error"
+)
+
+// ErrorPayload is the payload of an error message: the server refused one
+// of the client's messages
+type ErrorPayload struct {
+	Code    ErrorCode   `json:"code"`
+	Message string      `json:"message"`         // 사람이 읽을 설명
+	Type    MessageType `json:"type,omitempty"`  // 거절한 메시지의 타입
+	ID      string      `json:"id,omitempty"`    // 거절한 메시지의 id (클라이언트가 붙였다면)
+	Field   string      `json:"field,omitempty"` // 문제가 된 payload 필드
+}
@@ -6,7 +6,7 @@ import "sync"
 type GameState struct {
 	Players      map[string]*Player `json:"players"`
 	PlayerCount  int                `json:"playerCount"`  // 총 플레이어 수
-	Mu           sync.RWMutex
+	Mu           sync.RWMutex `json:"-"`
 }
 
 // NewGameState creates a new game state
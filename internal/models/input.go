@@ -0,0 +1,12 @@
+package models
+
+// PlayerInput is one input message from a client. The server queues it and
+// applies it at the start of the next tick; the player's LastInputSeq then
+// tells the client which of its predicted inputs the server has processed.
+type PlayerInput struct {
+	Seq         uint32  `json:"seq"`
+	Key         string  `json:"key,omitempty"` // WASD 키
+	HasVelocity bool    `json:"-"`             // Vx/Vy가 채워진 터치/클릭 입력
+	Vx          float64 `json:"vx,omitempty"`
+	Vy          float64 `json:"vy,omitempty"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// ObstacleShape is the kind of a static obstacle
+type ObstacleShape string
+
+const (
+	ShapeRect    ObstacleShape = "rect"    // 축 정렬 사각형: (x, y) 왼쪽 위, w x h
+	ShapeCircle  ObstacleShape = "circle"  // 원: 중심 (x, y), 반지름 r
+	ShapeSegment ObstacleShape = "segment" // 선분: (x, y) -> (x2, y2)
+)
+
+// Obstacle is a static piece of map geometry players bounce off
+type Obstacle struct {
+	Shape ObstacleShape `json:"shape" yaml:"shape"`
+	X     float64       `json:"x" yaml:"x"`
+	Y     float64       `json:"y" yaml:"y"`
+	W     float64       `json:"w,omitempty" yaml:"w"`
+	H     float64       `json:"h,omitempty" yaml:"h"`
+	R     float64       `json:"r,omitempty" yaml:"r"`
+	X2    float64       `json:"x2,omitempty" yaml:"x2"`
+	Y2    float64       `json:"y2,omitempty" yaml:"y2"`
+}
+
+// Point is a position in the arena
+type Point struct {
+	X float64 `json:"x" yaml:"x"`
+	Y float64 `json:"y" yaml:"y"`
+}
+
+// Map describes the arena: its bounds (0 keeps WorldConfig's), static
+// obstacles and the points new players spawn at
+type Map struct {
+	Name        string     `json:"name,omitempty" yaml:"name"`
+	Width       float64    `json:"width,omitempty" yaml:"width"`
+	Height      float64    `json:"height,omitempty" yaml:"height"`
+	Obstacles   []Obstacle `json:"obstacles,omitempty" yaml:"obstacles"`
+	SpawnPoints []Point    `json:"spawnPoints,omitempty" yaml:"spawnPoints"`
+}
+
+// Closest returns the point of the obstacle nearest to (x, y), and whether
+// (x, y) is inside it (rectangles and circles only). For a point inside, the
+// nearest point is on the boundary.
+func (o Obstacle) Closest(x, y float64) (cx, cy float64, inside bool) {
+	switch o.Shape {
+	case ShapeRect:
+		cx = max(o.X, min(o.X+o.W, x))
+		cy = max(o.Y, min(o.Y+o.H, y))
+		if cx != x || cy != y {
+			return cx, cy, false
+		}
+		// 안쪽: 가장 가까운 변으로
+		left, right := x-o.X, o.X+o.W-x
+		top, bottom := y-o.Y, o.Y+o.H-y
+		switch min(left, right, top, bottom) {
+		case left:
+			return o.X, y, true
+		case right:
+			return o.X + o.W, y, true
+		case top:
+			return x, o.Y, true
+		default:
+			return x, o.Y + o.H, true
+		}
+
+	case ShapeCircle:
+		dx, dy := x-o.X, y-o.Y
+		dist := math.Hypot(dx, dy)
+		if dist == 0 {
+			return o.X + o.R, o.Y, true
+		}
+		return o.X + dx/dist*o.R, o.Y + dy/dist*o.R, dist < o.R
+
+	case ShapeSegment:
+		sx, sy := o.X2-o.X, o.Y2-o.Y
+		t := 0.0
+		if lenSq := sx*sx + sy*sy; lenSq > 0 {
+			t = max(0, min(1, ((x-o.X)*sx+(y-o.Y)*sy)/lenSq))
+		}
+		return o.X + t*sx, o.Y + t*sy, false
+	}
+	return x, y, false
+}
+
+// Validate reports the first obstacle or spawn point that doesn't make sense
+// in a width x height arena
+func (m Map) Validate(width, height float64) error {
+	for i, o := range m.Obstacles {
+		switch o.Shape {
+		case ShapeRect:
+			if o.W <= 0 || o.H <= 0 {
+				return fmt.Errorf("obstacle %d: rect needs positive w and h", i)
+			}
+		case ShapeCircle:
+			if o.R <= 0 {
+				return fmt.Errorf("obstacle %d: circle needs a positive r", i)
+			}
+		case ShapeSegment:
+		default:
+			return fmt.Errorf("obstacle %d: unknown shape %q", i, o.Shape)
+		}
+	}
+	for i, p := range m.SpawnPoints {
+		if p.X < 0 || p.X > width || p.Y < 0 || p.Y > height {
+			return fmt.Errorf("spawn point %d (%g, %g) is outside the %gx%g arena", i, p.X, p.Y, width, height)
+		}
+	}
+	return nil
+}
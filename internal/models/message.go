@@ -3,15 +3,62 @@ package models
 // Message represents a websocket message
 type Message struct {
 	Type    MessageType `json:"type"`
+	ID      string      `json:"id,omitempty"` // 클라이언트가 붙이는 요청 id (선택), 거절되면 error에 그대로 돌아옴
 	Payload interface{} `json:"payload"`
 }
 
-// PlayerCollision represents a collision between two players
-type PlayerCollision struct {
-	MyID       string  `json:"myId"`
-	PartnerID  string  `json:"partnerId"`
-	MyNewX     float64 `json:"myNewX"`
-	MyNewY     float64 `json:"myNewY"`
-	PartnerX   float64 `json:"partnerX"`
-	PartnerY   float64 `json:"partnerY"`
-} 
\ No newline at end of file
+// WelcomePayload is the payload of the welcome message a client gets when
+// it logs in or resumes its player
+type WelcomePayload struct {
+	ID          string      `json:"id"`
+	PlayerNum   int         `json:"playerNum"`
+	Name        string      `json:"name"`
+	Color       string      `json:"color"`
+	Room        string      `json:"room"`
+	ResumeToken string      `json:"resumeToken"` // 재접속용 비밀 토큰
+	Resumed     bool        `json:"resumed"`     // 토큰으로 기존 플레이어를 되찾음
+	World       WorldConfig `json:"world"`
+}
+
+// CollisionEvent is a player-player collision resolved by the server's
+// physics tick. Clients use it for effects and sounds only; positions still
+// come from game_state.
+type CollisionEvent struct {
+	Tick    uint64  `json:"tick"`
+	A       string  `json:"a"` // 충돌한 플레이어 ID (ID 순서상 앞)
+	B       string  `json:"b"` // 충돌한 플레이어 ID
+	X       float64 `json:"x"` // 접촉점
+	Y       float64 `json:"y"`
+	NX      float64 `json:"nx"` // A → B 방향 단위 법선
+	NY      float64 `json:"ny"`
+	Impulse float64 `json:"impulse"` // 법선 방향 상대 속도 크기 (px/s)
+}
+
+// CollisionPayload is the payload of a server→client collision message: the
+// collisions of one tick
+type CollisionPayload struct {
+	Tick   uint64           `json:"tick"`
+	Events []CollisionEvent `json:"events"`
+}
+
+// SpectatePayload is the server's reply to a spectate message, also sent to
+// connections that open a replay
+type SpectatePayload struct {
+	Room   string      `json:"room"`
+	Follow string      `json:"follow"` // 요청한 플레이어 ID를 그대로 돌려줌
+	Delay  float64     `json:"delay"`  // 관전 지연 (ms)
+	World  WorldConfig `json:"world"`
+}
+
+// Announcement is the payload of an announcement sent from the admin API
+type Announcement struct {
+	Text   string `json:"text"`
+	SentAt int64  `json:"sentAt"` // 보낸 시각 (Unix ms)
+}
+
+// ServerShutdown is the payload of the server_shutdown message sent when the
+// server starts shutting down
+type ServerShutdown struct {
+	Seconds float64 `json:"seconds"` // 연결을 닫기까지 남은 시간 (초)
+	At      int64   `json:"at"`      // 연결을 닫는 예정 시각 (Unix ms)
+}
@@ -28,9 +28,48 @@ const (
 	// Player login
 	MessageTypeLogin MessageType = "login"
 	
-	// Player collision
+	// Player collisions resolved by the server (server → client only)
 	MessageTypeCollision MessageType = "collision"
 
 	// Player input
 	MessageTypeInput MessageType = "input"
+
+	// Move to another room
+	MessageTypeJoinRoom MessageType = "join_room"
+
+	// Client acknowledges a game_state snapshot
+	MessageTypeStateAck MessageType = "state_ack"
+
+	// Player came into the client's area of interest
+	MessageTypePlayerEnter MessageType = "player_enter"
+
+	// Player went out of the client's area of interest
+	MessageTypePlayerExit MessageType = "player_exit"
+
+	// Game mode round changed phase (server → client only)
+	MessageTypeRoundPhase MessageType = "round_phase"
+
+	// Chat message (client → server to send, server → client to deliver)
+	MessageTypeChat MessageType = "chat"
+
+	// Recent chat, sent after login (server → client only)
+	MessageTypeChatHistory MessageType = "chat_history"
+
+	// Watch the room without a player (client → server), and its reply
+	MessageTypeSpectate MessageType = "spectate"
+
+	// Server announcement from an admin (server → client only)
+	MessageTypeAnnouncement MessageType = "announcement"
+
+	// Server is about to shut down (server → client only)
+	MessageTypeServerShutdown MessageType = "server_shutdown"
+
+	// Change a replay's speed, pause it or seek (client → server)
+	MessageTypeReplayControl MessageType = "replay_control"
+
+	// Where a replay's playback is (server → client only)
+	MessageTypeReplayStatus MessageType = "replay_status"
+
+	// The server refused a client message (server → client only)
+	MessageTypeError MessageType = "error"
 ) 
\ No newline at end of file
@@ -8,37 +8,45 @@ import (
 
 // Player represents a connected player
 type Player struct {
-	ID          string    `json:"id"`
-	PlayerNum   int       `json:"playerNum"`   // 접속 순서 (1, 2, 3...)
-	Name        string    `json:"name"`        // 플레이어 이름 (Player 1, Player 2...)
-	X           float64   `json:"x"`
-	Y           float64   `json:"y"`
-	Vx          float64   `json:"vx"`
-	Vy          float64   `json:"vy"`
-	Color       string    `json:"color"`
-	JoinedAt    time.Time `json:"joinedAt"`    // 최초 접속 시간
-	LastSeen    time.Time `json:"lastSeen"`    // 마지막 활동 시간
-	Conn        *websocket.Conn
+	ID           string          `json:"id"`
+	PlayerNum    int             `json:"playerNum"` // 접속 순서 (1, 2, 3...)
+	Name         string          `json:"name"`      // 플레이어 이름 (Player 1, Player 2...)
+	X            float64         `json:"x"`
+	Y            float64         `json:"y"`
+	Vx           float64         `json:"vx"`
+	Vy           float64         `json:"vy"`
+	Color        string          `json:"color"`
+	JoinedAt     time.Time       `json:"joinedAt"`     // 최초 접속 시간
+	LastSeen     time.Time       `json:"lastSeen"`     // 마지막 활동 시간
+	LastInputSeq uint32          `json:"lastInputSeq"` // 서버가 마지막으로 처리한 입력 번호
+	Away         bool            `json:"away"`         // 연결이 끊겨 재접속을 기다리는 중 (입력 무시)
+	It           bool            `json:"it"`           // 술래 (tag 모드)
+	Score        int             `json:"score"`        // 이번 라운드 점수 (게임 모드가 관리)
+	Bot          bool            `json:"bot"`          // 서버가 조종하는 봇 (연결 없음)
+	Conn         *websocket.Conn `json:"-"`
 }
 
-// PlayerMove represents a player movement
+// PlayerMove represents a player movement (the payload of player_move)
 type PlayerMove struct {
-	X float64 `json:"x"`
-	Y float64 `json:"y"`
+	ID string  `json:"id"`
+	X  float64 `json:"x"`
+	Y  float64 `json:"y"`
 }
 
-// PlayerJoin represents a player joining event
+// PlayerJoin represents a player joining event (the payload of player_join
+// and player_enter)
 type PlayerJoin struct {
-	ID    string `json:"id"`
-	Color string `json:"color"`
+	ID        string  `json:"id"`
+	PlayerNum int     `json:"playerNum"`
+	Name      string  `json:"name"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Color     string  `json:"color"`
+	Bot       bool    `json:"bot"`
 }
 
-// PlayerLogin represents a player login request
-type PlayerLogin struct {
-	Name         string  `json:"name"`
-	Color        string  `json:"color"`
-	LastPosition *struct {
-		X float64 `json:"x"`
-		Y float64 `json:"y"`
-	} `json:"lastPosition"`
-} 
\ No newline at end of file
+// PlayerLeave represents a player leaving event (the payload of
+// player_leave and player_exit)
+type PlayerLeave struct {
+	ID string `json:"id"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// ReplayEventKind is what a replay event does to the world
+type ReplayEventKind string
+
+const (
+	ReplayInput    ReplayEventKind = "input"    // Game.QueueInput
+	ReplayJoin     ReplayEventKind = "join"     // Game.AddPlayer
+	ReplayLeave    ReplayEventKind = "leave"    // Game.RemovePlayer
+	ReplayAway     ReplayEventKind = "away"     // Game.SetAway
+	ReplayTeleport ReplayEventKind = "teleport" // Game.UpdatePlayerPosition
+	ReplayCheck    ReplayEventKind = "check"    // 재시뮬레이션 검증용 상태 해시 (월드는 그대로)
+	ReplayEnd      ReplayEventKind = "end"      // 녹화 끝
+)
+
+// ReplayEvent is one change made to a game between two ticks. Tick is the
+// tick it belongs to: events with Tick n are applied, in recorded order,
+// right before the game runs tick n.
+type ReplayEvent struct {
+	Tick   uint64          `json:"t"`
+	Kind   ReplayEventKind `json:"k"`
+	Player string          `json:"p,omitempty"`
+
+	// input
+	Seq         uint32  `json:"seq,omitempty"`
+	Key         string  `json:"key,omitempty"`
+	HasVelocity bool    `json:"hv,omitempty"`
+	Vx          float64 `json:"vx,omitempty"`
+	Vy          float64 `json:"vy,omitempty"`
+
+	X    float64 `json:"x,omitempty"`    // teleport 목표 위치
+	Y    float64 `json:"y,omitempty"`    // teleport
+	Away bool    `json:"away,omitempty"` // away
+	Join *Player `json:"join,omitempty"` // join: 들어온 플레이어 (JoinedAt 포함)
+	Hash uint64  `json:"hash,omitempty"` // check
+}
+
+// Input returns the input an input event carries
+func (ev ReplayEvent) Input() PlayerInput {
+	return PlayerInput{Seq: ev.Seq, Key: ev.Key, HasVelocity: ev.HasVelocity, Vx: ev.Vx, Vy: ev.Vy}
+}
+
+// WorldSnapshot is a game's whole simulation state, enough to carry on
+// exactly where it was: where a replay starts
+type WorldSnapshot struct {
+	Tick        uint64         `json:"tick"`
+	PlayerCount int            `json:"playerCount"`
+	Players     []Player       `json:"players"` // ID 순
+	Seed        int64          `json:"seed"`    // 게임 모드 난수 시드
+	Round       *RoundSnapshot `json:"round,omitempty"`
+}
+
+// RoundSnapshot is a round's state machine plus its game mode's own state
+type RoundSnapshot struct {
+	Phase   RoundPhase    `json:"phase"`
+	Number  int           `json:"number"`
+	Left    time.Duration `json:"left"`
+	Results []RoundResult `json:"results,omitempty"`
+	Mode    []byte        `json:"mode,omitempty"` // 모드 내부 상태 (형식은 모드가 정함)
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// 클라이언트 → 서버 메시지의 payload. 서버는 메시지 타입마다 정해진
+// 구조체로 디코딩한 뒤 Validate로 필수 필드와 범위를 확인하고, 통과하지
+// 못한 메시지는 error 메시지로 거절한다.
+
+// FieldError is a request field that is missing, malformed or out of range
+type FieldError struct {
+	Field  string // payload 안의 경로 (예: lastPosition.x), payload 전체면 ""
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Reason
+	}
+	return e.Field + " " + e.Reason
+}
+
+// 플레이어 색상 형식 (#RRGGBB)
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// PlayerLogin represents a player login request
+type PlayerLogin struct {
+	Name         string `json:"name"`                   // 재접속 토큰이 있으면 생략 가능
+	Color        string `json:"color,omitempty"`        // 비우면 서버가 고름
+	LastPosition *Point `json:"lastPosition,omitempty"` // 이전 접속의 마지막 위치 (아레나 안), 없으면 무작위
+	ResumeToken  string `json:"resumeToken,omitempty"`  // 이전 welcome의 재접속 토큰
+}
+
+// Validate reports the first field the server can't accept. The name may be
+// left out when resuming with a token.
+func (l PlayerLogin) Validate() error {
+	switch {
+	case strings.TrimSpace(l.Name) == "" && l.ResumeToken == "":
+		return &FieldError{Field: "name", Reason: "is required"}
+	case l.Color != "" && !colorPattern.MatchString(l.Color):
+		return &FieldError{Field: "color", Reason: "must be a #RRGGBB color"}
+	case l.LastPosition != nil && !finite(l.LastPosition.X):
+		return &FieldError{Field: "lastPosition.x", Reason: "must be a finite number"}
+	case l.LastPosition != nil && !finite(l.LastPosition.Y):
+		return &FieldError{Field: "lastPosition.y", Reason: "must be a finite number"}
+	}
+	return nil
+}
+
+// 입력 메시지로 보낼 수 있는 키
+var inputKeys = []string{"w", "a", "s", "d"}
+
+// InputRequest is the payload of an input message: a WASD key, a
+// touch/click velocity, or both
+type InputRequest struct {
+	Seq uint32   `json:"seq"` // 클라이언트가 매기는 입력 번호 (선택, lastInputSeq로 돌아옴)
+	Key string   `json:"key,omitempty"`
+	Vx  *float64 `json:"vx,omitempty"` // 속도 입력은 vx, vy가 함께 와야 함 (0이어도 보냄)
+	Vy  *float64 `json:"vy,omitempty"`
+}
+
+// HasVelocity reports whether the input carries a velocity
+func (r InputRequest) HasVelocity() bool {
+	return r.Vx != nil && r.Vy != nil
+}
+
+// Validate reports the first field the server can't accept
+func (r InputRequest) Validate() error {
+	switch {
+	case r.Key != "" && !slices.Contains(inputKeys, r.Key):
+		return &FieldError{Field: "key", Reason: `must be one of "w", "a", "s", "d"`}
+	case (r.Vx == nil) != (r.Vy == nil):
+		return &FieldError{Field: "vx", Reason: "and vy must be sent together"}
+	case r.Key == "" && !r.HasVelocity():
+		return &FieldError{Reason: "key or vx and vy is required"}
+	case r.Vx != nil && !finite(*r.Vx):
+		return &FieldError{Field: "vx", Reason: "must be a finite number"}
+	case r.Vy != nil && !finite(*r.Vy):
+		return &FieldError{Field: "vy", Reason: "must be a finite number"}
+	}
+	return nil
+}
+
+// JoinRoomRequest is the payload of a join_room message
+type JoinRoomRequest struct {
+	Room string `json:"room"`
+}
+
+// Validate reports a missing room. Whether the ID is usable is up to the
+// room manager.
+func (r JoinRoomRequest) Validate() error {
+	if r.Room == "" {
+		return &FieldError{Field: "room", Reason: "is required"}
+	}
+	return nil
+}
+
+// Validate reports a missing snapshot number
+func (a StateAck) Validate() error {
+	if a.Seq == 0 {
+		return &FieldError{Field: "seq", Reason: "must be a snapshot number (1 or more)"}
+	}
+	return nil
+}
+
+// ReconnectRequest is the payload of a reconnect message
+type ReconnectRequest struct {
+	Token string `json:"token"`
+}
+
+// Validate reports a missing token
+func (r ReconnectRequest) Validate() error {
+	if r.Token == "" {
+		return &FieldError{Field: "token", Reason: "is required"}
+	}
+	return nil
+}
+
+// SpectateRequest is the payload of a spectate message from a client
+type SpectateRequest struct {
+	Follow string `json:"follow"` // 따라갈 플레이어 ID (선택)
+}
+
+// Validate accepts every spectate request
+func (SpectateRequest) Validate() error {
+	return nil
+}
+
+// ChatRequest is the payload of a chat message from a client
+type ChatRequest struct {
+	Channel ChatChannel `json:"channel"`
+	To      string      `json:"to"` // dm 받는 플레이어 ID
+	Text    string      `json:"text"`
+}
+
+// Validate reports the first field the server can't accept. Length, rate
+// and filter limits are the chat service's.
+func (r ChatRequest) Validate() error {
+	switch {
+	case r.Channel != ChatGlobal && r.Channel != ChatRoom && r.Channel != ChatDirect:
+		return &FieldError{Field: "channel", Reason: `must be "global", "room" or "dm"`}
+	case r.Channel == ChatDirect && r.To == "":
+		return &FieldError{Field: "to", Reason: "is required for dm"}
+	case r.Text == "":
+		return &FieldError{Field: "text", Reason: "is required"}
+	}
+	return nil
+}
+
+// ReplayControlRequest is the payload of a replay_control message; any
+// combination of the fields may be set
+type ReplayControlRequest struct {
+	Speed  *float64 `json:"speed"`
+	Paused *bool    `json:"paused"`
+	Seek   *uint64  `json:"seek"` // 이동할 tick
+}
+
+// Validate reports an empty request
+func (r ReplayControlRequest) Validate() error {
+	if r.Speed == nil && r.Paused == nil && r.Seek == nil {
+		return &FieldError{Reason: "speed, paused or seek is required"}
+	}
+	return nil
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
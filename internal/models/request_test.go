@@ -0,0 +1,75 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestRequestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   interface{ Validate() error }
+		field string // 거절할 때 가리킬 필드, ok면 무시
+		ok    bool
+	}{
+		{"login", PlayerLogin{Name: "ada", Color: "#a1B2c3", LastPosition: &Point{X: 1, Y: 2}}, "", true},
+		{"login without name", PlayerLogin{Name: "  "}, "name", false},
+		{"resume without name", PlayerLogin{ResumeToken: "tok"}, "", true},
+		{"login with a bad color", PlayerLogin{Name: "ada", Color: "red"}, "color", false},
+		{"login with a short color", PlayerLogin{Name: "ada", Color: "#fff"}, "color", false},
+		{"login at NaN", PlayerLogin{Name: "ada", LastPosition: &Point{X: math.NaN()}}, "lastPosition.x", false},
+		{"login at infinity", PlayerLogin{Name: "ada", LastPosition: &Point{Y: math.Inf(-1)}}, "lastPosition.y", false},
+
+		{"key input", InputRequest{Key: "w"}, "", true},
+		{"velocity input", InputRequest{Vx: ptr(0.0), Vy: ptr(0.0)}, "", true},
+		{"key and velocity", InputRequest{Key: "d", Vx: ptr(1.0), Vy: ptr(-1.0)}, "", true},
+		{"unknown key", InputRequest{Key: "W"}, "key", false},
+		{"vx without vy", InputRequest{Vx: ptr(1.0)}, "vx", false},
+		{"vy without vx", InputRequest{Key: "w", Vy: ptr(1.0)}, "vx", false},
+		{"empty input", InputRequest{Seq: 3}, "", false},
+		{"NaN velocity", InputRequest{Vx: ptr(math.NaN()), Vy: ptr(0.0)}, "vx", false},
+		{"infinite velocity", InputRequest{Vx: ptr(0.0), Vy: ptr(math.Inf(1))}, "vy", false},
+
+		{"join_room", JoinRoomRequest{Room: "b"}, "", true},
+		{"join_room without room", JoinRoomRequest{}, "room", false},
+
+		{"state_ack", StateAck{Seq: 1}, "", true},
+		{"state_ack of zero", StateAck{}, "seq", false},
+
+		{"reconnect", ReconnectRequest{Token: "t"}, "", true},
+		{"reconnect without token", ReconnectRequest{}, "token", false},
+
+		{"spectate", SpectateRequest{}, "", true},
+
+		{"room chat", ChatRequest{Channel: ChatRoom, Text: "hi"}, "", true},
+		{"dm", ChatRequest{Channel: ChatDirect, To: "p2", Text: "hi"}, "", true},
+		{"chat on an unknown channel", ChatRequest{Channel: "team", Text: "hi"}, "channel", false},
+		{"dm without to", ChatRequest{Channel: ChatDirect, Text: "hi"}, "to", false},
+		{"chat without text", ChatRequest{Channel: ChatGlobal}, "text", false},
+
+		{"replay seek", ReplayControlRequest{Seek: ptr(uint64(0))}, "", true},
+		{"replay pause", ReplayControlRequest{Paused: ptr(false)}, "", true},
+		{"empty replay_control", ReplayControlRequest{}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.ok {
+				if err != nil {
+					t.Errorf("refused: %v", err)
+				}
+				return
+			}
+			var fieldErr *FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("got %v, want a *FieldError", err)
+			}
+			if fieldErr.Field != tt.field {
+				t.Errorf("refused field %q (%v), want %q", fieldErr.Field, err, tt.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// RoundPhase is where a game mode's round is in its lifecycle
+type RoundPhase string
+
+const (
+	PhaseWaiting   RoundPhase = "waiting"   // 최소 인원을 기다리는 중
+	PhaseCountdown RoundPhase = "countdown" // 라운드 시작 전 카운트다운
+	PhasePlaying   RoundPhase = "playing"   // 라운드 진행 중
+	PhaseResults   RoundPhase = "results"   // 결과 표시
+	PhaseReset     RoundPhase = "reset"     // 점수/역할 초기화 (곧바로 waiting으로)
+)
+
+// RoundConfig selects the game mode and times its rounds. An empty Mode is
+// the free-roam sandbox, which has no rounds.
+type RoundConfig struct {
+	Mode          string `json:"mode" yaml:"mode"`                         // "" (sandbox), "tag"
+	MinPlayers    int    `json:"minPlayers" yaml:"minPlayers"`             // 카운트다운을 시작할 최소 인원
+	CountdownSecs int    `json:"countdownSeconds" yaml:"countdownSeconds"` // 카운트다운 길이 (초)
+	RoundSecs     int    `json:"roundSeconds" yaml:"roundSeconds"`         // 라운드 길이 (초)
+	ResultsSecs   int    `json:"resultsSeconds" yaml:"resultsSeconds"`     // 결과 표시 시간 (초)
+}
+
+// DefaultRoundConfig returns the round timing used when a mode is picked
+// without setting it
+func DefaultRoundConfig() RoundConfig {
+	return RoundConfig{
+		MinPlayers:    2,
+		CountdownSecs: 5,
+		RoundSecs:     90,
+		ResultsSecs:   8,
+	}
+}
+
+// Countdown returns CountdownSecs as a time.Duration
+func (c RoundConfig) Countdown() time.Duration {
+	return time.Duration(c.CountdownSecs) * time.Second
+}
+
+// Length returns RoundSecs as a time.Duration
+func (c RoundConfig) Length() time.Duration {
+	return time.Duration(c.RoundSecs) * time.Second
+}
+
+// Results returns ResultsSecs as a time.Duration
+func (c RoundConfig) Results() time.Duration {
+	return time.Duration(c.ResultsSecs) * time.Second
+}
+
+// Validate reports the first timing that can't work
+func (c RoundConfig) Validate() error {
+	if c.Mode == "" {
+		return nil
+	}
+	switch {
+	case c.MinPlayers < 1:
+		return errors.New("minPlayers must be at least 1")
+	case c.CountdownSecs < 0 || c.ResultsSecs < 0:
+		return errors.New("countdownSeconds and resultsSeconds must not be negative")
+	case c.RoundSecs <= 0:
+		return errors.New("roundSeconds must be positive")
+	}
+	return nil
+}
+
+// RoundState is the payload of a round_phase message, sent whenever the
+// phase changes and to players joining mid-round
+type RoundState struct {
+	Mode      string        `json:"mode"`
+	Phase     RoundPhase    `json:"phase"`
+	Round     int           `json:"round"`             // 라운드 번호 (1부터, waiting/countdown은 다음 라운드 번호)
+	Remaining float64       `json:"remaining"`         // 이 phase가 끝날 때까지 남은 시간 (초), 0 = 제한 없음
+	Results   []RoundResult `json:"results,omitempty"` // results: 점수 높은 순
+}
+
+// RoundResult is one player's score at the end of a round
+type RoundResult struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
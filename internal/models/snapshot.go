@@ -0,0 +1,214 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// PlayerState is the part of a player that is sent in game_state snapshots
+type PlayerState struct {
+	ID        string    `json:"id"`
+	PlayerNum int       `json:"playerNum"`
+	Name      string    `json:"name"`
+	X         float64   `json:"x"`
+	Y         float64   `json:"y"`
+	Vx        float64   `json:"vx"`
+	Vy        float64   `json:"vy"`
+	Color     string    `json:"color"`
+	JoinedAt  time.Time `json:"joinedAt"`
+
+	LastInputSeq uint32 `json:"lastInputSeq"`   // 서버가 처리한 마지막 입력 번호 (클라이언트 보정용)
+	Away         bool   `json:"away,omitempty"` // 연결이 끊겨 재접속 대기 중
+	It           bool   `json:"it,omitempty"`   // 술래 (tag 모드)
+	Score        int    `json:"score,omitempty"`
+	Bot          bool   `json:"bot,omitempty"` // 서버 봇
+}
+
+// PlayerDelta carries only the fields of a player that changed since the
+// client's baseline snapshot. A player missing from the baseline is diffed
+// against an empty PlayerState, so fields at their zero value are left out;
+// clients apply the delta to an empty state to get the player.
+type PlayerDelta struct {
+	PlayerNum *int       `json:"playerNum,omitempty"`
+	Name      *string    `json:"name,omitempty"`
+	X         *float64   `json:"x,omitempty"`
+	Y         *float64   `json:"y,omitempty"`
+	Vx        *float64   `json:"vx,omitempty"`
+	Vy        *float64   `json:"vy,omitempty"`
+	Color     *string    `json:"color,omitempty"`
+	JoinedAt  *time.Time `json:"joinedAt,omitempty"`
+
+	LastInputSeq *uint32 `json:"lastInputSeq,omitempty"`
+	Away         *bool   `json:"away,omitempty"`
+	It           *bool   `json:"it,omitempty"`
+	Score        *int    `json:"score,omitempty"`
+	Bot          *bool   `json:"bot,omitempty"`
+}
+
+// GameStatePayload is the payload of a game_state message.
+// Every state carries the server tick it was taken at, the server time and
+// the tick interval so clients can interpolate between snapshots.
+// A full snapshot fills Players; a delta fills Changed and Removed relative
+// to the snapshot numbered Baseline.
+type GameStatePayload struct {
+	Seq          uint32                 `json:"seq"`
+	Tick         uint64                 `json:"tick"`
+	ServerTime   int64                  `json:"serverTime"`   // 스냅샷 시각 (Unix ms)
+	TickInterval float64                `json:"tickInterval"` // 물리 tick 간격 (ms)
+	Full         bool                   `json:"full"`
+	Baseline     uint32                 `json:"baseline,omitempty"`
+	Players      map[string]PlayerState `json:"players,omitempty"`
+	Changed      map[string]PlayerDelta `json:"changed,omitempty"`
+	Removed      []string               `json:"removed,omitempty"`
+}
+
+// Time returns ServerTime as a time.Time
+func (s GameStatePayload) Time() time.Time {
+	return time.UnixMilli(s.ServerTime)
+}
+
+// Interval returns TickInterval as a time.Duration
+func (s GameStatePayload) Interval() time.Duration {
+	return time.Duration(s.TickInterval * float64(time.Millisecond))
+}
+
+// StateAck is sent by the client to confirm it has applied a snapshot
+type StateAck struct {
+	Seq uint32 `json:"seq"`
+}
+
+// 스냅샷 좌표/속도 양자화 배율 (0.01px, 0.01px/s 단위).
+// 미세한 변화로 델타가 계속 생기는 것을 막는다.
+const (
+	positionScale = 100
+	velocityScale = 100
+)
+
+func quantize(v, scale float64) float64 {
+	return math.Round(v*scale) / scale
+}
+
+// State returns the snapshot view of the player, with positions and
+// velocities quantized
+func (p *Player) State() PlayerState {
+	return PlayerState{
+		ID:        p.ID,
+		PlayerNum: p.PlayerNum,
+		Name:      p.Name,
+		X:         quantize(p.X, positionScale),
+		Y:         quantize(p.Y, positionScale),
+		Vx:        quantize(p.Vx, velocityScale),
+		Vy:        quantize(p.Vy, velocityScale),
+		Color:     p.Color,
+		JoinedAt:  p.JoinedAt,
+
+		LastInputSeq: p.LastInputSeq,
+		Away:         p.Away,
+		It:           p.It,
+		Score:        p.Score,
+		Bot:          p.Bot,
+	}
+}
+
+// Diff returns the fields of s that differ from old, and whether there were any
+func (s PlayerState) Diff(old PlayerState) (PlayerDelta, bool) {
+	var d PlayerDelta
+	changed := false
+	if s.PlayerNum != old.PlayerNum {
+		d.PlayerNum = &s.PlayerNum
+		changed = true
+	}
+	if s.Name != old.Name {
+		d.Name = &s.Name
+		changed = true
+	}
+	if s.X != old.X {
+		d.X = &s.X
+		changed = true
+	}
+	if s.Y != old.Y {
+		d.Y = &s.Y
+		changed = true
+	}
+	if s.Vx != old.Vx {
+		d.Vx = &s.Vx
+		changed = true
+	}
+	if s.Vy != old.Vy {
+		d.Vy = &s.Vy
+		changed = true
+	}
+	if s.Color != old.Color {
+		d.Color = &s.Color
+		changed = true
+	}
+	if !s.JoinedAt.Equal(old.JoinedAt) {
+		d.JoinedAt = &s.JoinedAt
+		changed = true
+	}
+	if s.LastInputSeq != old.LastInputSeq {
+		d.LastInputSeq = &s.LastInputSeq
+		changed = true
+	}
+	if s.Away != old.Away {
+		d.Away = &s.Away
+		changed = true
+	}
+	if s.It != old.It {
+		d.It = &s.It
+		changed = true
+	}
+	if s.Score != old.Score {
+		d.Score = &s.Score
+		changed = true
+	}
+	if s.Bot != old.Bot {
+		d.Bot = &s.Bot
+		changed = true
+	}
+	return d, changed
+}
+
+// Apply returns s with the delta's fields applied
+func (s PlayerState) Apply(d PlayerDelta) PlayerState {
+	if d.PlayerNum != nil {
+		s.PlayerNum = *d.PlayerNum
+	}
+	if d.Name != nil {
+		s.Name = *d.Name
+	}
+	if d.X != nil {
+		s.X = *d.X
+	}
+	if d.Y != nil {
+		s.Y = *d.Y
+	}
+	if d.Vx != nil {
+		s.Vx = *d.Vx
+	}
+	if d.Vy != nil {
+		s.Vy = *d.Vy
+	}
+	if d.Color != nil {
+		s.Color = *d.Color
+	}
+	if d.JoinedAt != nil {
+		s.JoinedAt = *d.JoinedAt
+	}
+	if d.LastInputSeq != nil {
+		s.LastInputSeq = *d.LastInputSeq
+	}
+	if d.Away != nil {
+		s.Away = *d.Away
+	}
+	if d.It != nil {
+		s.It = *d.It
+	}
+	if d.Score != nil {
+		s.Score = *d.Score
+	}
+	if d.Bot != nil {
+		s.Bot = *d.Bot
+	}
+	return s
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WorldConfig is the arena size, physics constants and limits a game runs
+// with. The server loads it once (see internal/config) and sends it to every
+// client in welcome so they render the same arena.
+type WorldConfig struct {
+	Width        float64 `json:"width" yaml:"width"`
+	Height       float64 `json:"height" yaml:"height"`
+	PlayerRadius float64 `json:"playerRadius" yaml:"playerRadius"`
+
+	KeySpeed    float64 `json:"keySpeed" yaml:"keySpeed"`       // WASD 한 번에 더해지는 속도 (px/s)
+	MaxSpeed    float64 `json:"maxSpeed" yaml:"maxSpeed"`       // 터치/클릭 이동 최대 속도 (px/s)
+	BlendFactor float64 `json:"blendFactor" yaml:"blendFactor"` // 기존 속도와 새로운 속도의 혼합 비율
+	Friction    float64 `json:"friction" yaml:"friction"`       // 1/60초마다 곱해지는 마찰 계수
+	Restitution float64 `json:"restitution" yaml:"restitution"` // 벽 충돌 시 반동 계수
+
+	MaxPlayers    int `json:"maxPlayers" yaml:"maxPlayers"`       // 방마다 최대 플레이어 수 (0 = 제한 없음)
+	MaxNameLength int `json:"maxNameLength" yaml:"maxNameLength"` // 이름 최대 글자 수
+
+	Map   Map         `json:"map" yaml:"map"`     // 장애물과 스폰 지점
+	Round RoundConfig `json:"round" yaml:"round"` // 게임 모드와 라운드 시간
+	Bots  BotConfig   `json:"bots" yaml:"bots"`   // 서버 봇
+}
+
+// DefaultWorldConfig returns the 800x600 arena the game has always used
+func DefaultWorldConfig() WorldConfig {
+	return WorldConfig{
+		Width:        800,
+		Height:       600,
+		PlayerRadius: 15,
+
+		KeySpeed:    150,
+		MaxSpeed:    480,
+		BlendFactor: 0.3,
+		Friction:    0.98,
+		Restitution: 0.7,
+
+		MaxPlayers:    0,
+		MaxNameLength: 20,
+
+		Round: DefaultRoundConfig(),
+		Bots:  DefaultBotConfig(),
+	}
+}
+
+// MinDistance is how close two player centers can get before they collide
+func (c WorldConfig) MinDistance() float64 {
+	return c.PlayerRadius * 2
+}
+
+// Contains reports whether (x, y) is inside the arena
+func (c WorldConfig) Contains(x, y float64) bool {
+	return x >= 0 && x <= c.Width && y >= 0 && y <= c.Height
+}
+
+// Clamp moves (x, y) inside the arena, keeping a player's whole body in it
+func (c WorldConfig) Clamp(x, y float64) (float64, float64) {
+	x = max(c.PlayerRadius, min(c.Width-c.PlayerRadius, x))
+	y = max(c.PlayerRadius, min(c.Height-c.PlayerRadius, y))
+	return x, y
+}
+
+// Validate reports the first setting that can't work
+func (c WorldConfig) Validate() error {
+	switch {
+	case c.PlayerRadius <= 0:
+		return errors.New("playerRadius must be positive")
+	case c.Width < c.MinDistance() || c.Height < c.MinDistance():
+		return fmt.Errorf("arena %gx%g is too small for players of radius %g", c.Width, c.Height, c.PlayerRadius)
+	case c.KeySpeed < 0 || c.MaxSpeed <= 0:
+		return errors.New("keySpeed must not be negative and maxSpeed must be positive")
+	case c.BlendFactor < 0 || c.BlendFactor > 1:
+		return errors.New("blendFactor must be between 0 and 1")
+	case c.Friction <= 0 || c.Friction > 1:
+		return errors.New("friction must be in (0, 1]")
+	case c.Restitution < 0 || c.Restitution > 1:
+		return errors.New("restitution must be between 0 and 1")
+	case c.MaxPlayers < 0 || c.MaxNameLength < 0:
+		return errors.New("maxPlayers and maxNameLength must not be negative")
+	}
+	if err := c.Map.Validate(c.Width, c.Height); err != nil {
+		return fmt.Errorf("map: %w", err)
+	}
+	if err := c.Round.Validate(); err != nil {
+		return fmt.Errorf("round: %w", err)
+	}
+	if err := c.Bots.Validate(c.Width, c.Height); err != nil {
+		return fmt.Errorf("bots: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package replay
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/game"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// Speeds are the playback speeds a viewer can pick
+var Speeds = []float64{0.5, 1, 4}
+
+// ErrBadSpeed is returned by SetSpeed for a speed not in Speeds
+var ErrBadSpeed = errors.New("speed must be 0.5, 1 or 4")
+
+// 한 번의 Advance에서 실행하는 최대 tick 수 (4배속에서도 밀리면 버림)
+const maxAdvanceTicks = 40
+
+// Playback re-simulates a replay through its own Game, tick by tick, at a
+// chosen speed. The room that shows it calls Advance from its tick loop;
+// viewers control it with SetSpeed, SetPaused and Seek.
+type Playback struct {
+	name string
+	rep  *Replay
+	game *game.Game
+	step time.Duration
+
+	mu         sync.Mutex
+	next       int // 다음에 적용할 이벤트
+	speed      float64
+	paused     bool
+	acc        time.Duration
+	mismatches int // 녹화와 상태 해시가 달랐던 check 수
+}
+
+// Status is where a playback is, as sent to viewers
+type Status struct {
+	File       string    `json:"file"`
+	Room       string    `json:"room"` // 녹화한 방
+	StartedAt  time.Time `json:"startedAt"`
+	SimRate    int       `json:"simRate"`
+	StartTick  uint64    `json:"startTick"`
+	EndTick    uint64    `json:"endTick"`
+	Tick       uint64    `json:"tick"`
+	Speed      float64   `json:"speed"`
+	Paused     bool      `json:"paused"`
+	Ended      bool      `json:"ended"`
+	Complete   bool      `json:"complete"`   // 녹화가 정상적으로 끝남
+	Mismatches int       `json:"mismatches"` // 재시뮬레이션 결과가 녹화와 달랐던 횟수
+}
+
+// NewPlayback prepares rep, read from the file name, for playback from its
+// first tick
+func NewPlayback(name string, rep *Replay) (*Playback, error) {
+	p := &Playback{
+		name:  name,
+		rep:   rep,
+		game:  game.NewGame(rep.World),
+		step:  time.Second / time.Duration(rep.SimRate),
+		speed: 1,
+	}
+	if err := p.game.Restore(rep.Start); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Game returns the game the replay is re-simulated in
+func (p *Playback) Game() *game.Game {
+	return p.game
+}
+
+// Advance plays the replay on by elapsed wall time, scaled by the speed, and
+// returns the collisions of the ticks it ran
+func (p *Playback) Advance(elapsed time.Duration) []models.CollisionEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused || p.ended() {
+		return nil
+	}
+	p.acc += time.Duration(float64(elapsed) * p.speed)
+	p.acc = min(p.acc, maxAdvanceTicks*p.step)
+
+	var collisions []models.CollisionEvent
+	for p.acc >= p.step && !p.ended() {
+		collisions = append(collisions, p.tick()...)
+		p.acc -= p.step
+	}
+	return collisions
+}
+
+// tick applies the events of the next tick and runs it. The caller must hold
+// p.mu.
+func (p *Playback) tick() []models.CollisionEvent {
+	n := p.game.TickCount() + 1
+	for ; p.next < len(p.rep.Events) && p.rep.Events[p.next].Tick <= n; p.next++ {
+		ev := p.rep.Events[p.next]
+		if ev.Kind == models.ReplayCheck {
+			p.check(ev)
+			continue
+		}
+		p.game.Apply(ev)
+	}
+	return p.game.Tick(p.step)
+}
+
+// check compares the world with the hash recorded at the same point
+func (p *Playback) check(ev models.ReplayEvent) {
+	if sum := p.game.Checksum(); sum != ev.Hash {
+		if p.mismatches == 0 {
+			log.Printf("Replay %s diverged from the recording before tick %d", p.name, ev.Tick)
+		}
+		p.mismatches++
+	}
+}
+
+// ended reports whether every tick has been played. The caller must hold
+// p.mu.
+func (p *Playback) ended() bool {
+	return p.game.TickCount() >= p.rep.EndTick()
+}
+
+// SetSpeed changes the playback speed to one of Speeds
+func (p *Playback) SetSpeed(speed float64) error {
+	if !slices.Contains(Speeds, speed) {
+		return ErrBadSpeed
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.speed = speed
+	return nil
+}
+
+// SetPaused stops or resumes playback
+func (p *Playback) SetPaused(paused bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = paused
+	p.acc = 0
+}
+
+// Seek jumps to tick, clamped to the replay. Going back restarts from the
+// first tick; either way the ticks in between are re-simulated, so the world
+// is exactly what it was at that tick.
+func (p *Playback) Seek(tick uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tick = max(p.rep.Start.Tick, min(tick, p.rep.EndTick()))
+	if tick < p.game.TickCount() {
+		if err := p.game.Restore(p.rep.Start); err != nil {
+			return err
+		}
+		p.next = 0
+		p.mismatches = 0
+	}
+	for p.game.TickCount() < tick {
+		p.tick()
+	}
+	// 건너뛴 구간의 라운드 변화는 보내지 않음 (round_phase는 다음 변화부터)
+	p.game.TakeRoundChanges()
+	p.acc = 0
+	return nil
+}
+
+// Status returns where the playback is
+func (p *Playback) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Status{
+		File:       p.name,
+		Room:       p.rep.Room,
+		StartedAt:  p.rep.StartedAt,
+		SimRate:    p.rep.SimRate,
+		StartTick:  p.rep.Start.Tick,
+		EndTick:    p.rep.EndTick(),
+		Tick:       p.game.TickCount(),
+		Speed:      p.speed,
+		Paused:     p.paused,
+		Ended:      p.ended(),
+		Complete:   p.rep.Complete,
+		Mismatches: p.mismatches,
+	}
+}
@@ -0,0 +1,116 @@
+package replay
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/game"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 버퍼에 쌓인 이벤트를 파일에 쓰는 간격
+const flushInterval = time.Second
+
+// Recorder records one game into a replay file. The game hands it events
+// with its lock held, so they are only buffered there and written to the
+// file by a goroutine of the recorder's own.
+type Recorder struct {
+	game *game.Game
+	path string
+
+	mu      sync.Mutex
+	pending []models.ReplayEvent // 아직 파일에 쓰지 않은 이벤트
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Start starts recording g, which runs simRate ticks per second in the given
+// room and world, into a new file at path
+func Start(g *game.Game, path, room string, simRate int) (*Recorder, error) {
+	rec := &Recorder{
+		game: g,
+		path: path,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	// 헤더를 쓰는 동안 들어오는 이벤트는 pending에 쌓임
+	start := g.StartRecording(rec)
+	w, err := Create(path, Header{
+		Room:      room,
+		StartedAt: time.Now(),
+		SimRate:   simRate,
+		World:     g.Config(),
+		Start:     start,
+	})
+	if err != nil {
+		g.StopRecording()
+		return nil, err
+	}
+	go rec.run(w)
+	return rec, nil
+}
+
+// Path returns the file being recorded into
+func (rec *Recorder) Path() string {
+	return rec.path
+}
+
+// Record implements game.Recorder
+func (rec *Recorder) Record(ev models.ReplayEvent) {
+	rec.mu.Lock()
+	rec.pending = append(rec.pending, ev)
+	rec.mu.Unlock()
+}
+
+// Stop ends the recording and closes the file; it is safe to call more than
+// once
+func (rec *Recorder) Stop() {
+	rec.once.Do(func() {
+		rec.game.StopRecording()
+		close(rec.stop)
+	})
+	<-rec.done
+}
+
+// run writes buffered events every flushInterval until Stop
+func (rec *Recorder) run(w *Writer) {
+	defer close(rec.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	failed := false
+	write := func() {
+		rec.mu.Lock()
+		events := rec.pending
+		rec.pending = nil
+		rec.mu.Unlock()
+		if failed || len(events) == 0 {
+			return
+		}
+		err := w.Write(events...)
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			// 이후 이벤트는 버리고 지금까지 쓴 부분만 남김
+			log.Printf("Error recording replay %s, stopping: %v", rec.path, err)
+			failed = true
+		}
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			write()
+		case <-rec.stop:
+			write()
+			if err := w.Close(); err != nil && !failed {
+				log.Printf("Error closing replay %s: %v", rec.path, err)
+			}
+			return
+		}
+	}
+}
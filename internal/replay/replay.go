@@ -0,0 +1,225 @@
+// Package replay records a room's game into a compact file and plays it
+// back. A replay is the state the recording started from plus every change
+// made to the world between ticks (inputs, joins, leaves, teleports), each
+// stamped with its tick: re-simulating them through Game.Tick rebuilds the
+// session exactly, which is how "my player teleported" reports are looked
+// into.
+//
+// File format: a gzip stream of MessagePack values (field names follow the
+// json tags), first a Header, then one models.ReplayEvent after another.
+package replay
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Version is the file format version written in every header
+const Version = 1
+
+// Ext is the extension of replay files
+const Ext = ".replay"
+
+// Header is the start of a replay file
+type Header struct {
+	Version   int                  `json:"version"`
+	Room      string               `json:"room"`
+	StartedAt time.Time            `json:"startedAt"`
+	SimRate   int                  `json:"simRate"` // 초당 tick 수
+	World     models.WorldConfig   `json:"world"`   // 재시뮬레이션할 월드 설정
+	Start     models.WorldSnapshot `json:"start"`
+}
+
+// Replay is a whole replay file in memory
+type Replay struct {
+	Header
+	Events []models.ReplayEvent // tick 순
+
+	// Complete is false when the file has no end event: the server stopped
+	// without closing it, or it is still being written
+	Complete bool
+
+	end uint64 // 녹화가 끝난 tick
+}
+
+// EndTick returns the last tick the replay covers
+func (r *Replay) EndTick() uint64 {
+	if r.Complete {
+		return r.end
+	}
+	// 끝나지 않은 파일: 마지막 이벤트가 속한 tick까지
+	end := r.Start.Tick
+	if n := len(r.Events); n > 0 {
+		end = max(end, r.Events[n-1].Tick)
+	}
+	return end
+}
+
+// Duration returns how long the replay lasts at normal speed
+func (r *Replay) Duration() time.Duration {
+	return time.Duration(r.EndTick()-r.Start.Tick) * time.Second / time.Duration(r.SimRate)
+}
+
+// 파일 이름: 영문/숫자/-/_/. 만 허용 (경로 구분자나 ".."로 디렉터리를 벗어나지 않도록)
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]{0,127}$`)
+
+// ValidName reports whether name can be used as a replay file name inside a
+// replay directory
+func ValidName(name string) bool {
+	return namePattern.MatchString(name) && filepath.Ext(name) == Ext
+}
+
+// Writer writes a replay file
+type Writer struct {
+	file *os.File
+	buf  *bufio.Writer
+	zw   *gzip.Writer
+	enc  *msgpack.Encoder
+}
+
+// Create creates the file at path and writes the header
+func Create(path string, h Header) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{file: f, buf: bufio.NewWriter(f)}
+	w.zw = gzip.NewWriter(w.buf)
+	w.enc = newEncoder(w.zw)
+	h.Version = Version
+	if err := w.enc.Encode(h); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("writing replay header: %w", err)
+	}
+	return w, nil
+}
+
+func newEncoder(w io.Writer) *msgpack.Encoder {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	enc.SetOmitEmpty(true)
+	enc.UseCompactInts(true)
+	enc.UseCompactFloats(true)
+	return enc
+}
+
+// Write appends events to the file
+func (w *Writer) Write(events ...models.ReplayEvent) error {
+	for _, ev := range events {
+		if err := w.enc.Encode(ev); err != nil {
+			return fmt.Errorf("writing replay event: %w", err)
+		}
+	}
+	return nil
+}
+
+// Flush pushes everything written so far to the file, so a crash loses at
+// most what came after
+func (w *Writer) Flush() error {
+	if err := w.zw.Flush(); err != nil {
+		return err
+	}
+	return w.buf.Flush()
+}
+
+// Close finishes the gzip stream and closes the file
+func (w *Writer) Close() error {
+	err := w.zw.Close()
+	if ferr := w.buf.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ErrNotReplay is returned by Read for data that isn't a replay file
+var ErrNotReplay = errors.New("not a replay file")
+
+// Open reads the replay file at path
+func Open(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads a whole replay. A file cut short (by a crash, or because it is
+// still being recorded) is read up to the last complete event.
+func Read(r io.Reader) (*Replay, error) {
+	zr, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, ErrNotReplay
+	}
+	dec := msgpack.NewDecoder(zr)
+	dec.SetCustomStructTag("json")
+
+	rep := &Replay{}
+	if err := dec.Decode(&rep.Header); err != nil {
+		return nil, ErrNotReplay
+	}
+	if rep.Version != Version {
+		return nil, fmt.Errorf("unsupported replay version %d", rep.Version)
+	}
+	if rep.SimRate <= 0 {
+		return nil, fmt.Errorf("replay has an invalid sim rate %d", rep.SimRate)
+	}
+	for {
+		var ev models.ReplayEvent
+		if err := dec.Decode(&ev); err != nil {
+			// EOF, 또는 끝까지 쓰이지 않은 꼬리
+			break
+		}
+		if ev.Kind == models.ReplayEnd {
+			// end 이벤트는 다음 tick 앞에 붙으므로 마지막으로 실행된 tick은 하나 앞
+			rep.Complete = true
+			rep.end = max(rep.Start.Tick, ev.Tick-1)
+			break
+		}
+		rep.Events = append(rep.Events, ev)
+	}
+	return rep, nil
+}
+
+// Info describes a replay file in a directory
+type Info struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// List returns the replay files in dir, oldest first
+func List(dir string) ([]Info, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]Info, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !ValidName(e.Name()) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, Info{Name: e.Name(), Size: fi.Size(), Modified: fi.ModTime()})
+	}
+	// 파일 이름 순으로는 방 이름끼리 묶이므로 수정 시각으로 정렬
+	slices.SortFunc(infos, func(a, b Info) int { return a.Modified.Compare(b.Modified) })
+	return infos, nil
+}
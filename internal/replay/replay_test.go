@@ -0,0 +1,255 @@
+package replay
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/game"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 녹화할 tick 수: check 이벤트가 여럿, tag 라운드가 한 번 끝날 만큼
+const recordTicks = 8 * game.TickRate
+
+// recordGame records a tag game with joins, leaves, away players, a teleport
+// and random inputs into dir, and returns the file with the checksum the
+// live game had after every tick
+func recordGame(t *testing.T, dir string) (string, map[uint64]uint64) {
+	t.Helper()
+	cfg := models.DefaultWorldConfig()
+	cfg.Round = models.RoundConfig{Mode: "tag", MinPlayers: 2, CountdownSecs: 1, RoundSecs: 4, ResultsSecs: 1}
+	g := game.NewGame(cfg)
+	for i, id := range []string{"p1", "p2", "p3"} {
+		g.AddPlayer(&models.Player{ID: id, Name: id, X: 200 + 150*float64(i), Y: 300})
+	}
+	// 녹화 전에 쌓인 입력도 녹화에 들어가야 함
+	g.ApplyInput("p1", "d", 1)
+
+	path := filepath.Join(dir, "lobby"+Ext)
+	rec, err := Start(g, path, "lobby", game.TickRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewPCG(3, 4))
+	sums := map[uint64]uint64{g.TickCount(): g.Checksum()}
+	seq := uint32(1)
+	for g.TickCount() < recordTicks {
+		switch g.TickCount() {
+		case 60:
+			g.AddPlayer(&models.Player{ID: "p4", Name: "p4", X: 100, Y: 100})
+		case 120:
+			g.SetAway("p2", true)
+		case 150:
+			g.SetAway("p2", false)
+		case 200:
+			g.RemovePlayer("p3")
+		case 250:
+			g.UpdatePlayerPosition("p1", 400, 300)
+		}
+		for _, id := range []string{"p1", "p2", "p3", "p4"} {
+			seq++
+			switch rng.IntN(4) {
+			case 0:
+				g.ApplyInput(id, []string{"w", "a", "s", "d"}[rng.IntN(4)], seq)
+			case 1:
+				g.ApplyVelocityInput(id, rng.Float64()*400-200, rng.Float64()*400-200, seq)
+			}
+		}
+		g.Tick(game.TickInterval)
+		sums[g.TickCount()] = g.Checksum()
+	}
+	rec.Stop()
+	return path, sums
+}
+
+func TestPlaybackMatchesRecording(t *testing.T) {
+	path, sums := recordGame(t, t.TempDir())
+	rep, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rep.Complete || rep.EndTick() != recordTicks {
+		t.Fatalf("complete=%v end=%d, want a complete replay ending at %d", rep.Complete, rep.EndTick(), recordTicks)
+	}
+	checks := 0
+	for _, ev := range rep.Events {
+		if ev.Kind == models.ReplayCheck {
+			checks++
+		}
+	}
+	if checks != recordTicks/game.TickRate {
+		t.Errorf("%d check events, want one every %d ticks", checks, game.TickRate)
+	}
+
+	pb, err := NewPlayback("lobby"+Ext, rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 앞으로, 다시 처음부터(뒤로), 끝까지
+	for _, tick := range []uint64{0, 1, 59, 61, 200, 251, 100, recordTicks} {
+		if err := pb.Seek(tick); err != nil {
+			t.Fatal(err)
+		}
+		if got := pb.Game().Checksum(); got != sums[tick] {
+			t.Errorf("after seeking to tick %d: checksum %x, recording had %x", tick, got, sums[tick])
+		}
+	}
+	if st := pb.Status(); st.Mismatches != 0 || !st.Ended || st.Tick != recordTicks {
+		t.Errorf("status %+v, want ended at %d without mismatches", st, recordTicks)
+	}
+}
+
+func TestAdvanceMatchesSeek(t *testing.T) {
+	path, sums := recordGame(t, t.TempDir())
+	rep, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb, err := NewPlayback("lobby"+Ext, rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pb.SetSpeed(4); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; !pb.Status().Ended; i++ {
+		if i > recordTicks {
+			t.Fatal("playback never ended")
+		}
+		pb.Advance(17 * time.Millisecond)
+		if tick := pb.Game().TickCount(); pb.Game().Checksum() != sums[tick] {
+			t.Fatalf("tick %d: checksum differs from the recording", tick)
+		}
+	}
+	if m := pb.Status().Mismatches; m != 0 {
+		t.Errorf("%d mismatches, want 0", m)
+	}
+}
+
+func TestPlaybackDetectsDivergence(t *testing.T) {
+	path, _ := recordGame(t, t.TempDir())
+
+	tests := []struct {
+		name   string
+		tamper func(events []models.ReplayEvent) []models.ReplayEvent
+		want   int
+	}{
+		{"untouched", func(events []models.ReplayEvent) []models.ReplayEvent { return events }, 0},
+		{"one check hash changed", func(events []models.ReplayEvent) []models.ReplayEvent {
+			i := slices.IndexFunc(events, func(ev models.ReplayEvent) bool { return ev.Kind == models.ReplayCheck })
+			events[i].Hash ^= 1
+			return events
+		}, 1},
+		{"an early input dropped", func(events []models.ReplayEvent) []models.ReplayEvent {
+			i := slices.IndexFunc(events, func(ev models.ReplayEvent) bool { return ev.Kind == models.ReplayInput && ev.Tick > 10 })
+			return slices.Delete(events, i, i+1)
+		}, -1},
+		{"teleport moved", func(events []models.ReplayEvent) []models.ReplayEvent {
+			i := slices.IndexFunc(events, func(ev models.ReplayEvent) bool { return ev.Kind == models.ReplayTeleport })
+			events[i].X++
+			return events
+		}, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rep, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rep.Events = tt.tamper(rep.Events)
+			pb, err := NewPlayback("lobby"+Ext, rep)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := pb.Seek(rep.EndTick()); err != nil {
+				t.Fatal(err)
+			}
+			got := pb.Status().Mismatches
+			// -1: 그 뒤의 check가 하나 이상 어긋나면 됨
+			if tt.want < 0 && got == 0 || tt.want >= 0 && got != tt.want {
+				t.Errorf("%d mismatches, want %s", got, wantMismatches(tt.want))
+			}
+		})
+	}
+}
+
+func wantMismatches(n int) string {
+	if n < 0 {
+		return "at least one"
+	}
+	return fmt.Sprint(n)
+}
+
+func TestChecksum(t *testing.T) {
+	newGame := func(players ...models.Player) *game.Game {
+		g := game.NewGame(models.DefaultWorldConfig())
+		for _, p := range players {
+			g.AddPlayer(&p)
+		}
+		return g
+	}
+	a := models.Player{ID: "a", X: 100, Y: 100, Vx: 10}
+	b := models.Player{ID: "b", X: 300, Y: 200}
+	base := newGame(a, b).Checksum()
+
+	if got := newGame(b, a).Checksum(); got != base {
+		t.Error("checksum depends on the order players joined in")
+	}
+	// 물리와 무관한 필드는 해시에 들어가지 않음
+	named := a
+	named.Name, named.Color = "alice", "#fff"
+	if got := newGame(named, b).Checksum(); got != base {
+		t.Error("checksum changed with the name and color")
+	}
+	for name, change := range map[string]func(p *models.Player){
+		"x":     func(p *models.Player) { p.X = 100.0000001 },
+		"vy":    func(p *models.Player) { p.Vy = -1 },
+		"score": func(p *models.Player) { p.Score = 1 },
+		"it":    func(p *models.Player) { p.It = true },
+	} {
+		changed := a
+		change(&changed)
+		if got := newGame(changed, b).Checksum(); got == base {
+			t.Errorf("checksum ignores %s", name)
+		}
+	}
+}
+
+func TestReadCutShort(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cut"+Ext)
+	w, err := Create(path, Header{Room: "lobby", SimRate: game.TickRate, Start: models.WorldSnapshot{Tick: 10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	events := []models.ReplayEvent{
+		{Tick: 11, Kind: models.ReplayInput, Player: "p1", Key: "w"},
+		{Tick: 25, Kind: models.ReplayLeave, Player: "p1"},
+	}
+	if err := w.Write(events...); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 서버가 죽어 gzip 스트림이 닫히지 않은 파일
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Complete || len(rep.Events) != 2 || rep.EndTick() != 25 {
+		t.Errorf("complete=%v events=%d end=%d, want an incomplete replay of 2 events ending at 25", rep.Complete, len(rep.Events), rep.EndTick())
+	}
+}
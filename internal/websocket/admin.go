@@ -0,0 +1,354 @@
+package ws
+
+import (
+	"cmp"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+	"github.com/sangjinsu/websocket-multiplayer/internal/replay"
+)
+
+// 관리자 API(internal/admin)가 쓰는 조작들
+
+// 관리자 요청을 처리할 수 없는 이유
+var (
+	ErrPlayerNotFound = errors.New("player not found")
+	ErrRoomNotFound   = errors.New("room not found")
+	ErrBadPosition    = errors.New("position is outside the arena or inside an obstacle")
+	ErrNoConnections  = errors.New("no connections from that address")
+)
+
+// PlayerInfo is what the admin API shows about one player
+type PlayerInfo struct {
+	models.PlayerState
+	Room string     `json:"room"`
+	Conn *ConnStats `json:"conn"` // 봇이거나 재접속 대기 중이면 nil
+}
+
+// RoomInfo is what the admin API shows about one room
+type RoomInfo struct {
+	ID          string             `json:"id"`
+	Players     int                `json:"players"`     // 월드에 있는 플레이어 (재접속 대기 포함)
+	Connections int                `json:"connections"` // 로그인 전, 관전자 포함
+	Spectators  int                `json:"spectators"`
+	Bots        int                `json:"bots"`      // Players 중 봇
+	BotTarget   int                `json:"botTarget"` // 봇으로 채우는 목표 인원
+	Paused      bool               `json:"paused"`
+	Tick        uint64             `json:"tick"`
+	Round       *models.RoundState `json:"round,omitempty"`
+	Recording   string             `json:"recording,omitempty"` // 녹화 중인 리플레이 파일
+	Replay      *replay.Status     `json:"replay,omitempty"`    // 리플레이 재생 방이면 재생 상태
+}
+
+// Players lists every player in the world, in any room, with the stats of
+// the connection driving it. Bots have no connection and are listed with
+// bot set.
+func (m *RoomManager) Players() []PlayerInfo {
+	type entry struct {
+		room   *Room
+		player *models.Player
+		client *Client
+	}
+	m.sessions.mu.Lock()
+	entries := make([]entry, 0, len(m.sessions.byPlayer))
+	for _, s := range m.sessions.byPlayer {
+		entries = append(entries, entry{s.room, s.player, s.client})
+	}
+	m.sessions.mu.Unlock()
+
+	players := make([]PlayerInfo, 0, len(entries))
+	for _, e := range entries {
+		state, ok := e.room.game.PlayerState(e.player.ID)
+		if !ok {
+			continue
+		}
+		info := PlayerInfo{PlayerState: state, Room: e.room.ID}
+		if e.client != nil {
+			stats := e.client.stats()
+			info.Conn = &stats
+		}
+		players = append(players, info)
+	}
+	// 봇은 세션이 없으므로 월드에서 직접 찾음 (리플레이 방의 플레이어는 녹화본이라 제외)
+	for _, room := range m.Rooms() {
+		if room.playback != nil {
+			continue
+		}
+		for _, p := range room.game.GetAllPlayers() {
+			if p.Bot {
+				players = append(players, PlayerInfo{PlayerState: p.State(), Room: room.ID})
+			}
+		}
+	}
+	slices.SortFunc(players, func(a, b PlayerInfo) int {
+		return cmp.Or(cmp.Compare(a.Room, b.Room), cmp.Compare(a.PlayerNum, b.PlayerNum))
+	})
+	return players
+}
+
+// RoomInfos lists the live rooms, sorted by ID
+func (m *RoomManager) RoomInfos() []RoomInfo {
+	rooms := m.Rooms()
+	infos := make([]RoomInfo, 0, len(rooms))
+	for _, room := range rooms {
+		infos = append(infos, room.Info())
+	}
+	slices.SortFunc(infos, func(a, b RoomInfo) int { return cmp.Compare(a.ID, b.ID) })
+	return infos
+}
+
+// Info returns the room's counts and state
+func (r *Room) Info() RoomInfo {
+	info := RoomInfo{
+		ID:      r.ID,
+		Players: len(r.game.GetAllPlayers()),
+		Paused:  r.Paused(),
+		Tick:    r.game.TickCount(),
+	}
+	if r.playback == nil {
+		info.Bots = r.game.Bots().Count()
+		info.BotTarget = r.game.Bots().Target()
+	}
+	if st, ok := r.game.RoundState(); ok {
+		info.Round = &st
+	}
+	info.Recording = r.Recording()
+	if r.playback != nil {
+		st := r.playback.Status()
+		info.Replay = &st
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info.Connections = len(r.clients)
+	for client := range r.clients {
+		if client.spectator {
+			info.Spectators++
+		}
+	}
+	return info
+}
+
+// SetPaused stops or restarts the room's simulation. Clients stay connected
+// and keep getting game_state; the world and round timers just stand still.
+func (r *Room) SetPaused(paused bool) {
+	r.paused.Store(paused)
+}
+
+// Paused reports whether the room's simulation is stopped
+func (r *Room) Paused() bool {
+	return r.paused.Load()
+}
+
+// Kick disconnects a player and removes it from the world right away,
+// without the reconnect grace period
+func (m *RoomManager) Kick(playerID, reason string) error {
+	n := m.kickSessions(func(s *session) bool { return s.player.ID == playerID }, CloseKicked, reason)
+	if n == 0 {
+		return ErrPlayerNotFound
+	}
+	return nil
+}
+
+// Ban refuses new connections from ip and kicks everyone connected from it,
+// including spectators and connections that haven't logged in
+func (m *RoomManager) Ban(ip, reason string) {
+	m.mu.Lock()
+	m.banned[ip] = struct{}{}
+	m.mu.Unlock()
+
+	m.closeIP(ip, CloseBanned, reason)
+	log.Printf("Banned %s", ip)
+}
+
+// KickIP disconnects every connection from ip, including spectators and
+// connections that haven't logged in, and removes their players without the
+// reconnect grace period. Unlike Ban, the address may connect again.
+func (m *RoomManager) KickIP(ip, reason string) error {
+	if m.closeIP(ip, CloseKicked, reason) == 0 {
+		return ErrNoConnections
+	}
+	return nil
+}
+
+// closeIP closes every connection from ip with code and reason, and removes
+// players from ip that are waiting for a reconnect. It returns how many
+// connections and waiting players there were.
+func (m *RoomManager) closeIP(ip string, code int, reason string) int {
+	n := m.kickSessions(func(s *session) bool { return s.ip == ip }, code, reason)
+	for _, room := range m.Rooms() {
+		room.mu.RLock()
+		for client := range room.clients {
+			if client.ip == ip && !client.loggedIn {
+				client.CloseWith(code, reason)
+				n++
+			}
+		}
+		room.mu.RUnlock()
+	}
+	return n
+}
+
+// BanPlayer bans the address a player is connected from and returns it
+func (m *RoomManager) BanPlayer(playerID, reason string) (string, error) {
+	m.sessions.mu.Lock()
+	ip := ""
+	for _, s := range m.sessions.byPlayer {
+		if s.player.ID == playerID {
+			ip = s.ip
+			break
+		}
+	}
+	m.sessions.mu.Unlock()
+	if ip == "" {
+		return "", ErrPlayerNotFound
+	}
+	m.Ban(ip, reason)
+	return ip, nil
+}
+
+// Unban lets ip connect again and reports whether it was banned
+func (m *RoomManager) Unban(ip string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.banned[ip]
+	delete(m.banned, ip)
+	return ok
+}
+
+// Banned reports whether connections from ip are refused
+func (m *RoomManager) Banned(ip string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.banned[ip]
+	return ok
+}
+
+// Bans lists the banned addresses, sorted
+func (m *RoomManager) Bans() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ips := make([]string, 0, len(m.banned))
+	for ip := range m.banned {
+		ips = append(ips, ip)
+	}
+	slices.Sort(ips)
+	return ips
+}
+
+// kickSessions removes the players whose session matches and returns how
+// many there were. Connected ones are sent a close frame with code and
+// reason; leaveWorld then finds no session and removes them instead of
+// waiting for a reconnect. Players already waiting are removed here.
+func (m *RoomManager) kickSessions(match func(*session) bool, code int, reason string) int {
+	m.sessions.mu.Lock()
+	var kicked []*session
+	for _, s := range m.sessions.byPlayer {
+		if match(s) {
+			m.sessions.remove(s)
+			if s.timer != nil {
+				// 이미 만료가 시작됐어도 세션이 없으므로 expire는 아무것도 하지 않음
+				s.timer.Stop()
+			}
+			kicked = append(kicked, s)
+		}
+	}
+	m.sessions.mu.Unlock()
+
+	for _, s := range kicked {
+		log.Printf("Kicking player %s (%s) from room %s: %s", s.player.Name, s.player.ID, s.room.ID, reason)
+		if s.client != nil {
+			s.client.CloseWith(code, reason)
+			continue
+		}
+		s.room.game.RemovePlayer(s.player.ID)
+		s.room.broadcastPlayerLeave(s.player.ID)
+		m.Release(s.room)
+	}
+	return len(kicked)
+}
+
+// Teleport moves a player to (x, y), or next to whoever stands there, and
+// returns where it ended up
+func (m *RoomManager) Teleport(playerID string, x, y float64) (models.PlayerState, error) {
+	room := m.playerRoom(playerID)
+	if room == nil {
+		return models.PlayerState{}, ErrPlayerNotFound
+	}
+	if !room.game.UpdatePlayerPosition(playerID, x, y) {
+		return models.PlayerState{}, ErrBadPosition
+	}
+	state, ok := room.game.PlayerState(playerID)
+	if !ok {
+		return models.PlayerState{}, ErrPlayerNotFound
+	}
+	room.broadcastPlayerMove(state)
+	log.Printf("Teleported player %s (%s) to (%.1f, %.1f)", state.Name, state.ID, state.X, state.Y)
+	return state, nil
+}
+
+// playerRoom returns the room whose world holds the player, bots included,
+// or nil. Replay rooms are skipped: their players only replay what was
+// recorded.
+func (m *RoomManager) playerRoom(playerID string) *Room {
+	for _, room := range m.Rooms() {
+		if room.playback != nil {
+			continue
+		}
+		if _, ok := room.game.PlayerState(playerID); ok {
+			return room
+		}
+	}
+	return nil
+}
+
+// SetBotTarget has the room's bots fill the world up to n players; they
+// join or leave within a think interval
+func (r *Room) SetBotTarget(n int) error {
+	if r.playback != nil {
+		return ErrReplayRoom
+	}
+	r.game.Bots().SetTarget(n)
+	log.Printf("Room %s bot target set to %d", r.ID, n)
+	return nil
+}
+
+// Announce sends a server announcement to every connection in roomID, or in
+// every room when roomID is empty
+func (m *RoomManager) Announce(roomID, text string) error {
+	rooms := m.Rooms()
+	if roomID != "" {
+		room := m.Get(roomID)
+		if room == nil {
+			return ErrRoomNotFound
+		}
+		rooms = []*Room{room}
+	}
+	msg := models.Message{
+		Type: models.MessageTypeAnnouncement,
+		Payload: models.Announcement{
+			Text:   text,
+			SentAt: time.Now().UnixMilli(),
+		},
+	}
+	for _, room := range rooms {
+		room.sendAll(msg)
+	}
+	log.Printf("Announcement to %d room(s): %s", len(rooms), text)
+	return nil
+}
+
+// sendAll sends the message to every connection in the room, logged in or not
+func (r *Room) sendAll(message models.Message) {
+	frames := encodings{}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for client := range r.clients {
+		if data := frames.get(client.codec, message); data != nil {
+			client.enqueue(frame{data: data, typ: message.Type})
+		}
+	}
+}
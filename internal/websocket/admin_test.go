@@ -0,0 +1,110 @@
+package ws
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// closing reports whether a close frame is queued for client
+func closing(client *Client) bool {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	for _, f := range client.queue {
+		if f.close {
+			return true
+		}
+	}
+	return false
+}
+
+func TestKickIPClosesEveryConnectionFromIt(t *testing.T) {
+	h := newTestHandler(DefaultOptions())
+	player := h.connect("a")
+	h.login(t, player, "player")
+	spectator := h.connect("b")
+	h.spectate(spectator, "")
+	waiting := h.connect("a")
+	other := h.connect("a")
+	other.ip = "198.51.100.7"
+	for _, c := range []*Client{player, spectator, waiting, other} {
+		defer h.leaveRoom(c)
+	}
+
+	if err := h.rooms.KickIP(player.ip, "flooding"); err != nil {
+		t.Fatalf("KickIP: %v", err)
+	}
+	for name, c := range map[string]*Client{"player": player, "spectator": spectator, "not logged in": waiting} {
+		if !closing(c) {
+			t.Errorf("%s connection was not closed", name)
+		}
+	}
+	if closing(other) {
+		t.Error("connection from another address was closed")
+	}
+	if h.rooms.Banned(player.ip) {
+		t.Error("KickIP banned the address")
+	}
+	if s := h.rooms.sessions.byPlayer[player.player]; s != nil {
+		t.Error("kicked player still has a session to resume")
+	}
+
+	if err := h.rooms.KickIP("203.0.113.9", "nobody"); !errors.Is(err, ErrNoConnections) {
+		t.Errorf("KickIP with no connections: %v, want %v", err, ErrNoConnections)
+	}
+}
+
+func TestPlayersListsBots(t *testing.T) {
+	h := newTestHandler(DefaultOptions())
+	client := h.connect("a")
+	defer h.leaveRoom(client)
+	h.login(t, client, "ada")
+	client.room.game.Bots().SetTarget(2)
+	client.room.game.Bots().Update(time.Second)
+
+	players := h.rooms.Players()
+	if len(players) != 2 {
+		t.Fatalf("listed %d players, want ada and a bot", len(players))
+	}
+	for _, p := range players {
+		human := p.ID == client.player.ID
+		if p.Bot == human || (p.Conn != nil) != human || p.Room != "a" {
+			t.Errorf("listed %+v: bot %v with conn %v, want bot %v", p.PlayerState, p.Bot, p.Conn != nil, !human)
+		}
+	}
+}
+
+func TestTeleportBot(t *testing.T) {
+	h := newTestHandler(DefaultOptions())
+	client := h.connect("a")
+	defer h.leaveRoom(client)
+	room := client.room
+	room.game.Bots().SetTarget(1)
+
+	var bot models.PlayerState
+	deadline := time.Now().Add(2 * time.Second)
+	for bot.ID == "" {
+		for _, p := range room.game.GetAllPlayers() {
+			if p.Bot {
+				bot = p.State()
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no bot joined the room")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	state, err := h.rooms.Teleport(bot.ID, 400, 300)
+	if err != nil {
+		t.Fatalf("Teleport(%s): %v", bot.ID, err)
+	}
+	if state.ID != bot.ID || state.X != 400 || state.Y != 300 {
+		t.Errorf("bot teleported to %+v, want (400, 300)", state)
+	}
+	if _, err := h.rooms.Teleport("nobody", 400, 300); !errors.Is(err, ErrPlayerNotFound) {
+		t.Errorf("Teleport of an unknown player: %v, want %v", err, ErrPlayerNotFound)
+	}
+}
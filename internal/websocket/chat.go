@@ -0,0 +1,87 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/chat"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// chat delivers a chat message from the client's player: to everyone on the
+// server, to its room, or to one player (and back to the sender)
+func (h *Handler) chat(client *Client, channel models.ChatChannel, to, text string) {
+	player := client.player
+	room := client.room
+
+	var target *Client
+	if channel == models.ChatDirect {
+		if target = h.rooms.findClient(to); target == nil {
+			client.reject(models.ErrorCodePlayerNotFound, fmt.Errorf("player %q is not online", to))
+			return
+		}
+	}
+
+	msg, err := h.rooms.opts.Chat.Accept(player, room.ID, channel, to, text)
+	if err != nil {
+		client.reject(chatRejection(err))
+		return
+	}
+
+	out := models.Message{Type: models.MessageTypeChat, Payload: msg}
+	switch channel {
+	case models.ChatGlobal:
+		for _, r := range h.rooms.Rooms() {
+			r.broadcast(out, "")
+			r.sendSpectators(out)
+		}
+	case models.ChatRoom:
+		room.broadcast(out, "")
+		room.sendSpectators(out)
+	case models.ChatDirect:
+		target.Send(out)
+		if target != client {
+			client.Send(out)
+		}
+	}
+}
+
+// chatRejection is the error code and message a refused chat message is
+// answered with
+func chatRejection(err error) (models.ErrorCode, error) {
+	switch {
+	case errors.Is(err, chat.ErrRateLimited):
+		return models.ErrorCodeRateLimited, err
+	case errors.Is(err, chat.ErrFiltered):
+		return models.ErrorCodeFiltered, err
+	case errors.Is(err, chat.ErrEmpty):
+		return models.ErrorCodeInvalidPayload, invalidPayload(models.MessageTypeChat, &models.FieldError{Field: "text", Reason: "is blank"})
+	case errors.Is(err, chat.ErrTooLong):
+		return models.ErrorCodeInvalidPayload, invalidPayload(models.MessageTypeChat, &models.FieldError{Field: "text", Reason: "is too long"})
+	}
+	return models.ErrorCodeInvalidPayload, invalidPayload(models.MessageTypeChat, err)
+}
+
+// sendChatHistory sends a client that just joined the recent global chat and
+// its room's chat
+func (h *Handler) sendChatHistory(client *Client) {
+	client.Send(models.Message{
+		Type:    models.MessageTypeChatHistory,
+		Payload: h.rooms.opts.Chat.History(client.room.ID),
+	})
+}
+
+// findClient returns the logged-in connection of a player, in any room
+func (m *RoomManager) findClient(playerID string) *Client {
+	for _, room := range m.Rooms() {
+		room.mu.RLock()
+		for client := range room.clients {
+			if client.loggedIn && client.player.ID == playerID {
+				room.mu.RUnlock()
+				return client
+			}
+		}
+		room.mu.RUnlock()
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/websocket/v2"
+	"github.com/sangjinsu/websocket-multiplayer/internal/metrics"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 쓰기 한 번에 허용하는 최대 시간 (넘기면 연결이 죽은 것으로 본다)
+const writeWait = 10 * time.Second
+
+// QueuePolicy decides what happens when a client's outbound queue is full
+type QueuePolicy int
+
+const (
+	// DropStaleState discards the oldest queued game_state frame to make room.
+	// A newer state always supersedes an older one, so nothing is lost.
+	// If the queue holds no game_state frame the client is disconnected.
+	DropStaleState QueuePolicy = iota
+
+	// DisconnectSlow closes the connection as soon as the queue is full
+	DisconnectSlow
+)
+
+// 설정 파일과 환경 변수에서 쓰는 큐 정책 이름
+var queuePolicies = map[string]QueuePolicy{
+	"drop_stale": DropStaleState,
+	"disconnect": DisconnectSlow,
+}
+
+// ParseQueuePolicy returns the policy named in the config: "drop_stale" or
+// "disconnect"
+func ParseQueuePolicy(name string) (QueuePolicy, error) {
+	policy, ok := queuePolicies[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown queue policy %q (want \"drop_stale\" or \"disconnect\")", name)
+	}
+	return policy, nil
+}
+
+// 서버가 연결을 끊을 때 보내는 close 코드 (4000번대는 애플리케이션 정의)
+const (
+	CloseKicked   = 4001 // 관리자가 내보냄
+	CloseBanned   = 4003 // 차단된 IP
+	CloseNoReplay = 4004 // 요청한 리플레이가 없음 (/ws?replay=)
+)
+
+// frame is one encoded websocket message waiting in a client's queue
+type frame struct {
+	data  []byte
+	typ   models.MessageType // 메트릭용
+	stale bool               // 이후 game_state가 대체할 수 있는 프레임 (버려도 됨)
+	close bool               // close 프레임: 보낸 뒤 연결을 닫음
+}
+
+// closeReasons labels server-sent close codes in the disconnect metric
+var closeReasons = map[int]string{
+	CloseKicked:              "kicked",
+	CloseBanned:              "banned",
+	websocket.CloseGoingAway: "shutdown",
+}
+
+// Client is a single websocket connection and the room it currently belongs to.
+// All writes to the connection go through the client's own writer goroutine.
+type Client struct {
+	conn      *websocket.Conn
+	codec     Codec // 연결 시 협상한 와이어 인코딩
+	player    *models.Player
+	room      *Room
+	ip        string    // 접속한 주소 (프록시 뒤라면 Fiber가 알려준 클라이언트 주소)
+	connected time.Time // 접속 시각
+	loggedIn  bool      // login 처리 후 true (월드에 플레이어가 존재, Room.mu로 보호)
+	spectator bool      // 플레이어 없이 관전 중 (Room.mu로 보호)
+	handling  Envelope  // 처리 중인 메시지의 타입과 id, 거절할 때 error에 담음 (읽기 루프 전용)
+
+	policy    QueuePolicy
+	queueSize int
+
+	mu        sync.Mutex
+	queue     []frame
+	wake      chan struct{} // 큐에 프레임이 들어오면 writer를 깨움
+	done      chan struct{} // Close 시 닫힘
+	stopped   chan struct{} // writePump 종료 시 닫힘
+	closeOnce sync.Once
+	dropped   int    // DropStaleState로 버린 프레임 수
+	reason    string // 서버가 연결을 끊은 이유 (메트릭용, 처음 것만)
+
+	// 연결 통계 (관리자 API)
+	messagesIn atomic.Uint64
+	bytesIn    atomic.Uint64
+	framesOut  atomic.Uint64
+	bytesOut   atomic.Uint64
+
+	// 델타 스냅샷 상태
+	ackedSeq    atomic.Uint32 // 클라이언트가 마지막으로 적용했다고 알린 스냅샷
+	minAck      atomic.Uint32 // 이보다 오래된 ack는 이전 상태에 대한 것이므로 무시
+	needFull    atomic.Bool   // 다음 브로드캐스트에서 전체 스냅샷을 보내야 함
+	lastFullSeq uint32        // 마지막 전체 스냅샷 번호 (tick 루프 전용)
+	view        *interestView // 관심 영역 사용 시 이 연결이 보는 플레이어 (tick 루프 전용)
+}
+
+// newClient creates a client for conn; start its writer with writePump
+func newClient(conn *websocket.Conn, codec Codec, opts Options) *Client {
+	return &Client{
+		conn:      conn,
+		codec:     codec,
+		connected: time.Now(),
+		policy:    opts.QueuePolicy,
+		queueSize: opts.SendQueueSize,
+		queue:     make([]frame, 0, opts.SendQueueSize),
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// requestFullState forgets the client's acknowledged snapshot so the next
+// broadcast sends it a full snapshot
+func (c *Client) requestFullState() {
+	c.minAck.Store(math.MaxUint32)
+	c.ackedSeq.Store(0)
+	c.needFull.Store(true)
+}
+
+// ack records that the client has applied snapshot seq
+func (c *Client) ack(seq uint32) {
+	if seq < c.minAck.Load() {
+		return
+	}
+	for {
+		cur := c.ackedSeq.Load()
+		if seq <= cur || c.ackedSeq.CompareAndSwap(cur, seq) {
+			return
+		}
+	}
+}
+
+// Send encodes the message with the client's codec and queues it for the
+// writer goroutine
+func (c *Client) Send(message models.Message) {
+	data, err := c.codec.Encode(message)
+	if err != nil {
+		logEncodeError(c.codec, message, err)
+		return
+	}
+	c.enqueue(frame{data: data, typ: message.Type})
+}
+
+func logEncodeError(codec Codec, message models.Message, err error) {
+	metrics.MarshalErrors.WithLabelValues(codec.Name()).Inc()
+	log.Printf("Error encoding %s message as %s: %v", message.Type, codec.Name(), err)
+}
+
+// sendState queues an already-encoded game_state frame
+func (c *Client) sendState(data []byte) {
+	c.enqueue(frame{data: data, typ: models.MessageTypeGameState, stale: true})
+}
+
+// enqueue adds a frame to the bounded queue, applying the queue policy when
+// it is full. It never blocks, so it is safe to call from the tick loop.
+func (c *Client) enqueue(f frame) {
+	c.mu.Lock()
+	select {
+	case <-c.done:
+		c.mu.Unlock()
+		return
+	default:
+	}
+
+	if len(c.queue) >= c.queueSize {
+		if c.policy != DropStaleState || !c.dropOldestStale() {
+			c.mu.Unlock()
+			// tick 루프에서 불리므로 재접속 때 바뀌는 c.player 대신 주소를 남김
+			log.Printf("Send queue full for connection from %s, disconnecting", c.ip)
+			c.closeBecause("slow_client")
+			return
+		}
+	}
+	c.queue = append(c.queue, f)
+	c.mu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dropOldestStale removes the oldest game_state frame from the queue.
+// The caller must hold c.mu.
+func (c *Client) dropOldestStale() bool {
+	for i, f := range c.queue {
+		if f.stale {
+			c.queue = append(c.queue[:i], c.queue[i+1:]...)
+			c.dropped++
+			metrics.DroppedFrames.Inc()
+			return true
+		}
+	}
+	return false
+}
+
+// writePump writes queued frames to the connection until the client is closed.
+// It is the only goroutine that writes to c.conn.
+func (c *Client) writePump() {
+	defer close(c.stopped)
+	var batch []frame
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.wake:
+		}
+
+		c.mu.Lock()
+		batch, c.queue = c.queue, batch[:0]
+		c.mu.Unlock()
+
+		for _, f := range batch {
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if f.close {
+				_ = c.conn.WriteMessage(websocket.CloseMessage, f.data)
+				c.Close()
+				return
+			}
+			if err := c.conn.WriteMessage(c.codec.FrameType(), f.data); err != nil {
+				log.Printf("Error sending message: %v", err)
+				c.closeBecause("write_error")
+				return
+			}
+			c.framesOut.Add(1)
+			c.bytesOut.Add(uint64(len(f.data)))
+			metrics.MessagesSent.WithLabelValues(string(f.typ)).Inc()
+			metrics.BytesSent.Add(float64(len(f.data)))
+		}
+	}
+}
+
+// CloseWith sends a close frame with code and reason after the frames already
+// queued, then closes the connection
+func (c *Client) CloseWith(code int, reason string) {
+	c.setReason(closeReasons[code])
+	c.enqueue(frame{data: websocket.FormatCloseMessage(code, reason), close: true})
+}
+
+// closeBecause closes the connection, recording why for the disconnect metric
+func (c *Client) closeBecause(reason string) {
+	c.setReason(reason)
+	c.Close()
+}
+
+// setReason records why the server is closing the connection; the first
+// reason wins
+func (c *Client) setReason(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reason == "" {
+		c.reason = reason
+	}
+}
+
+// disconnectReason tells why the connection ended, given the error that
+// stopped the read loop: the server's reason if it closed the connection,
+// otherwise what the client did
+func (c *Client) disconnectReason(err error) string {
+	c.mu.Lock()
+	reason := c.reason
+	c.mu.Unlock()
+	var netErr net.Error
+	switch {
+	case reason != "":
+		return reason
+	case websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway):
+		return "client_closed"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	}
+	return "connection_lost"
+}
+
+// ConnStats is what the admin API shows about one connection
+type ConnStats struct {
+	IP          string    `json:"ip"`
+	Codec       string    `json:"codec"`
+	ConnectedAt time.Time `json:"connectedAt"`
+	MessagesIn  uint64    `json:"messagesIn"`
+	BytesIn     uint64    `json:"bytesIn"`
+	FramesOut   uint64    `json:"framesOut"`
+	BytesOut    uint64    `json:"bytesOut"`
+	Queued      int       `json:"queued"`  // 아직 보내지 못한 프레임 수
+	Dropped     int       `json:"dropped"` // 큐가 차서 버린 game_state 수
+	AckedSeq    uint32    `json:"ackedSeq"`
+}
+
+// stats returns the client's connection statistics
+func (c *Client) stats() ConnStats {
+	c.mu.Lock()
+	queued, dropped := len(c.queue), c.dropped
+	c.mu.Unlock()
+	return ConnStats{
+		IP:          c.ip,
+		Codec:       c.codec.Name(),
+		ConnectedAt: c.connected,
+		MessagesIn:  c.messagesIn.Load(),
+		BytesIn:     c.bytesIn.Load(),
+		FramesOut:   c.framesOut.Load(),
+		BytesOut:    c.bytesOut.Load(),
+		Queued:      queued,
+		Dropped:     dropped,
+		AckedSeq:    c.ackedSeq.Load(),
+	}
+}
+
+// Close stops the writer and closes the underlying connection. It is safe to
+// call more than once and from any goroutine.
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		close(c.done)
+		c.queue = nil
+		c.mu.Unlock()
+		_ = c.conn.Close()
+	})
+}
@@ -0,0 +1,131 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	fasthttpws "github.com/fasthttp/websocket"
+	"github.com/gofiber/websocket/v2"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// dialTestConn returns a live connection to a server that reads until the
+// connection closes, for clients that must be able to close it
+func dialTestConn(t *testing.T) *websocket.Conn {
+	t.Helper()
+	var upgrader fasthttpws.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	conn, _, err := fasthttpws.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &websocket.Conn{Conn: conn}
+}
+
+// queued lists the data of every frame waiting in c's queue
+func queued(c *Client) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]string, len(c.queue))
+	for i, f := range c.queue {
+		out[i] = string(f.data)
+	}
+	return out
+}
+
+func closed(c *Client) bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// newQueueTestClient returns a client whose writer never runs, so frames
+// stay queued
+func newQueueTestClient(t *testing.T, policy QueuePolicy, size int) *Client {
+	t.Helper()
+	opts := DefaultOptions()
+	opts.QueuePolicy = policy
+	opts.SendQueueSize = size
+	return newClient(dialTestConn(t), JSONCodec, opts)
+}
+
+func control(c *Client, data string) {
+	c.enqueue(frame{data: []byte(data), typ: models.MessageTypeChat})
+}
+
+func TestDropStaleStateDropsOldestState(t *testing.T) {
+	c := newQueueTestClient(t, DropStaleState, 4)
+	control(c, "a")
+	c.sendState([]byte("s1"))
+	c.sendState([]byte("s2"))
+	control(c, "b")
+
+	steps := []struct {
+		send func()
+		want []string
+	}{
+		{func() { c.sendState([]byte("s3")) }, []string{"a", "s2", "b", "s3"}},
+		{func() { control(c, "c") }, []string{"a", "b", "s3", "c"}},
+		{func() { control(c, "d") }, []string{"a", "b", "c", "d"}},
+	}
+	for i, step := range steps {
+		step.send()
+		if got := queued(c); !slices.Equal(got, step.want) {
+			t.Fatalf("step %d: queue %v, want %v", i, got, step.want)
+		}
+	}
+	if c.dropped != 3 || closed(c) {
+		t.Errorf("dropped %d, closed %v, want 3 dropped and still open", c.dropped, closed(c))
+	}
+
+	// 버릴 game_state가 없으면 control 메시지를 버리지 않고 끊음
+	control(c, "e")
+	if !closed(c) {
+		t.Fatalf("queue %v, want the connection closed", queued(c))
+	}
+	if got := c.disconnectReason(nil); got != "slow_client" {
+		t.Errorf("disconnect reason %q, want slow_client", got)
+	}
+}
+
+func TestDisconnectSlowClosesWhenFull(t *testing.T) {
+	c := newQueueTestClient(t, DisconnectSlow, 2)
+	c.sendState([]byte("s1"))
+	c.sendState([]byte("s2"))
+	if closed(c) {
+		t.Fatal("closed before the queue overflowed")
+	}
+
+	c.sendState([]byte("s3"))
+	if !closed(c) || c.dropped != 0 {
+		t.Fatalf("closed %v, dropped %d, want closed without dropping", closed(c), c.dropped)
+	}
+	if got := c.disconnectReason(nil); got != "slow_client" {
+		t.Errorf("disconnect reason %q, want slow_client", got)
+	}
+
+	// 닫힌 뒤에 보내는 프레임은 조용히 버림
+	control(c, "late")
+	if got := queued(c); len(got) != 0 {
+		t.Errorf("queue %v after close, want empty", got)
+	}
+}
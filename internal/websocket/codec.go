@@ -0,0 +1,143 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/gofiber/websocket/v2"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec is the wire encoding of one connection. Every message type in
+// models/message_types.go goes through it in both directions.
+type Codec interface {
+	// Name is the subprotocol / query value that selects the codec
+	Name() string
+
+	// FrameType is the websocket message type used for encoded frames
+	FrameType() int
+
+	Encode(message models.Message) ([]byte, error)
+
+	// Decode reads a client frame's envelope and leaves its payload
+	// encoded, for DecodePayload to read into the type's payload struct
+	Decode(data []byte) (Envelope, error)
+	DecodePayload(payload []byte, v any) error
+}
+
+// Envelope is a client message as read off the wire
+type Envelope struct {
+	Type    models.MessageType
+	ID      string // 클라이언트가 붙인 요청 id (선택)
+	Payload []byte // 인코딩된 그대로, payload가 없으면 nil
+}
+
+// Subprotocols lists the websocket subprotocols the server accepts, in
+// order of preference. Pass it to websocket.Config.
+var Subprotocols = []string{MsgpackCodec.Name(), JSONCodec.Name()}
+
+// 코덱 목록 (이름으로 조회)
+var (
+	JSONCodec    Codec = jsonCodec{}
+	MsgpackCodec Codec = msgpackCodec{}
+
+	codecs = map[string]Codec{
+		JSONCodec.Name():    JSONCodec,
+		MsgpackCodec.Name(): MsgpackCodec,
+	}
+)
+
+// negotiateCodec picks the connection's codec from the negotiated
+// subprotocol, then the ?encoding= query parameter, defaulting to JSON
+func negotiateCodec(c *websocket.Conn) Codec {
+	if codec, ok := codecs[c.Subprotocol()]; ok {
+		return codec
+	}
+	if codec, ok := codecs[c.Query("encoding")]; ok {
+		return codec
+	}
+	return JSONCodec
+}
+
+// jsonCodec is the default text encoding used by public/index.html
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string   { return "json" }
+func (jsonCodec) FrameType() int { return websocket.TextMessage }
+
+func (jsonCodec) Encode(message models.Message) ([]byte, error) {
+	return json.Marshal(message)
+}
+
+func (jsonCodec) Decode(data []byte) (Envelope, error) {
+	var message struct {
+		Type    models.MessageType `json:"type"`
+		ID      string             `json:"id"`
+		Payload json.RawMessage    `json:"payload"`
+	}
+	err := json.Unmarshal(data, &message)
+	return Envelope{Type: message.Type, ID: message.ID, Payload: message.Payload}, err
+}
+
+func (jsonCodec) DecodePayload(payload []byte, v any) error {
+	return json.Unmarshal(payload, v)
+}
+
+// msgpackCodec is the compact binary encoding (MessagePack) for clients on
+// slow networks. Field names follow the json tags so both encodings carry
+// the same documents.
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string   { return "msgpack" }
+func (msgpackCodec) FrameType() int { return websocket.BinaryMessage }
+
+func (msgpackCodec) Encode(message models.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	enc.UseCompactFloats(true)
+	if err := enc.Encode(message); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Decode(data []byte) (Envelope, error) {
+	var message struct {
+		Type    models.MessageType `json:"type"`
+		ID      string             `json:"id"`
+		Payload msgpack.RawMessage `json:"payload"`
+	}
+	err := newMsgpackDecoder(data).Decode(&message)
+	return Envelope{Type: message.Type, ID: message.ID, Payload: message.Payload}, err
+}
+
+func (msgpackCodec) DecodePayload(payload []byte, v any) error {
+	return newMsgpackDecoder(payload).Decode(v)
+}
+
+func newMsgpackDecoder(data []byte) *msgpack.Decoder {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec
+}
+
+// encodings caches one encoded frame per codec, so a broadcast encodes each
+// message once per wire format instead of once per client
+type encodings map[Codec][]byte
+
+// get returns message encoded with codec, encoding it on first use.
+// A nil result means encoding failed and has already been logged.
+func (e encodings) get(codec Codec, message models.Message) []byte {
+	if data, ok := e[codec]; ok {
+		return data
+	}
+	data, err := codec.Encode(message)
+	if err != nil {
+		logEncodeError(codec, message, err)
+	}
+	e[codec] = data
+	return data
+}
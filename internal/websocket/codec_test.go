@@ -0,0 +1,159 @@
+package ws
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+	"github.com/sangjinsu/websocket-multiplayer/internal/replay"
+)
+
+// roundTrip encodes payload as a msgType message with codec, decodes it back
+// into a T and fails unless it comes out the same
+func roundTrip[T any](msgType models.MessageType, payload T) func(t *testing.T, codec Codec) {
+	return func(t *testing.T, codec Codec) {
+		t.Helper()
+		data, err := codec.Encode(models.Message{Type: msgType, ID: "7", Payload: payload})
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		env, err := codec.Decode(data)
+		if err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if env.Type != msgType || env.ID != "7" {
+			t.Fatalf("envelope type=%q id=%q, want %q and 7", env.Type, env.ID, msgType)
+		}
+		var got T
+		if err := codec.DecodePayload(env.Payload, &got); err != nil {
+			t.Fatalf("decode payload: %v", err)
+		}
+		inUTC(reflect.ValueOf(&got).Elem())
+		if !reflect.DeepEqual(got, payload) {
+			t.Errorf("got  %+v\nwant %+v", got, payload)
+		}
+	}
+}
+
+// inUTC moves every time.Time reachable from v to UTC: msgpack decodes
+// timestamps in the local zone, which is the same instant but not DeepEqual
+func inUTC(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			inUTC(v.Elem())
+		}
+	case reflect.Struct:
+		if tm, ok := v.Interface().(time.Time); ok {
+			v.Set(reflect.ValueOf(tm.UTC()))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			inUTC(v.Field(i))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			inUTC(v.Index(i))
+		}
+	case reflect.Map:
+		// map 값은 주소를 얻을 수 없어 복사본을 고쳐 다시 넣음
+		for _, k := range v.MapKeys() {
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(v.MapIndex(k))
+			inUTC(e)
+			v.SetMapIndex(k, e)
+		}
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestCodecRoundTrip(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 15, 250_000_000, time.UTC)
+	world := models.DefaultWorldConfig()
+	chatMsg := models.ChatMessage{ID: 3, Channel: models.ChatRoom, Room: "lobby", From: "p1", FromName: "ada", Text: "안녕 👋", SentAt: at.UnixMilli()}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, codec Codec)
+	}{
+		{"welcome", roundTrip(models.MessageTypeWelcome, models.WelcomePayload{
+			ID: "p1", PlayerNum: 1, Name: "ada", Color: "#FF6B6B", Room: "lobby", ResumeToken: "tok", Resumed: true, World: world,
+		})},
+		{"game_state full", roundTrip(models.MessageTypeGameState, models.GameStatePayload{
+			Seq: 9, Tick: 540, ServerTime: at.UnixMilli(), TickInterval: 1000.0 / 60, Full: true,
+			Players: map[string]models.PlayerState{
+				"p1": {ID: "p1", PlayerNum: 1, Name: "ada", X: 10.5, Y: -3, Vx: 120, Vy: 0.25, Color: "#FF6B6B", JoinedAt: at, LastInputSeq: 42, It: true, Score: 3},
+				"p2": {ID: "p2", PlayerNum: 2, Name: "bot", X: 400, Y: 300, JoinedAt: at, Away: true, Bot: true},
+			},
+		})},
+		{"game_state delta", roundTrip(models.MessageTypeGameState, models.GameStatePayload{
+			Seq: 10, Tick: 541, ServerTime: at.UnixMilli(), TickInterval: 1000.0 / 60, Baseline: 9,
+			Changed: map[string]models.PlayerDelta{
+				"p1": {X: ptr(11.0), Vx: ptr(0.0), It: ptr(false), LastInputSeq: ptr(uint32(43))},
+				"p3": {PlayerNum: ptr(3), Name: ptr("new"), X: ptr(1.0), Y: ptr(2.0), Color: ptr("#fff"), JoinedAt: ptr(at)},
+			},
+			Removed: []string{"p2"},
+		})},
+		{"player_join", roundTrip(models.MessageTypePlayerJoin, models.PlayerJoin{ID: "p2", PlayerNum: 2, Name: "bob", X: 1, Y: 2, Color: "#00FF00", Bot: true})},
+		{"player_leave", roundTrip(models.MessageTypePlayerLeave, models.PlayerLeave{ID: "p2"})},
+		{"player_move", roundTrip(models.MessageTypePlayerMove, models.PlayerMove{ID: "p1", X: 100, Y: 200.5})},
+		{"move", roundTrip(models.MessageTypeMove, models.PlayerMove{ID: "p1", X: -1, Y: 0})},
+		{"reconnect", roundTrip(models.MessageTypeReconnect, models.ReconnectRequest{Token: "tok"})},
+		{"login", roundTrip(models.MessageTypeLogin, models.PlayerLogin{Name: "ada", Color: "#123456", LastPosition: &models.Point{X: 5, Y: 6}, ResumeToken: "tok"})},
+		{"collision", roundTrip(models.MessageTypeCollision, models.CollisionPayload{
+			Tick: 77, Events: []models.CollisionEvent{{Tick: 77, A: "p1", B: "p2", X: 10, Y: 20, NX: 0.6, NY: -0.8, Impulse: 153.25}},
+		})},
+		{"input key", roundTrip(models.MessageTypeInput, models.InputRequest{Seq: 4, Key: "w"})},
+		{"input velocity", roundTrip(models.MessageTypeInput, models.InputRequest{Seq: 5, Vx: ptr(0.0), Vy: ptr(-250.5)})},
+		{"join_room", roundTrip(models.MessageTypeJoinRoom, models.JoinRoomRequest{Room: "arena-2"})},
+		{"state_ack", roundTrip(models.MessageTypeStateAck, models.StateAck{Seq: 1 << 31})},
+		{"player_enter", roundTrip(models.MessageTypePlayerEnter, models.PlayerJoin{ID: "p3", PlayerNum: 3, Name: "cy", X: 7, Y: 8, Color: "#0000FF"})},
+		{"player_exit", roundTrip(models.MessageTypePlayerExit, models.PlayerLeave{ID: "p3"})},
+		{"round_phase", roundTrip(models.MessageTypeRoundPhase, models.RoundState{
+			Mode: "tag", Phase: models.PhaseResults, Round: 2, Remaining: 4.5,
+			Results: []models.RoundResult{{ID: "p1", Name: "ada", Score: 9}, {ID: "p2", Name: "bob", Score: 0}},
+		})},
+		{"chat request", roundTrip(models.MessageTypeChat, models.ChatRequest{Channel: models.ChatDirect, To: "p2", Text: "hi"})},
+		{"chat delivery", roundTrip(models.MessageTypeChat, chatMsg)},
+		{"chat_history", roundTrip(models.MessageTypeChatHistory, models.ChatHistory{Global: []models.ChatMessage{chatMsg}, Room: []models.ChatMessage{}})},
+		{"spectate request", roundTrip(models.MessageTypeSpectate, models.SpectateRequest{Follow: "p1"})},
+		{"spectate reply", roundTrip(models.MessageTypeSpectate, models.SpectatePayload{Room: "lobby", Follow: "p1", Delay: 1500, World: world})},
+		{"announcement", roundTrip(models.MessageTypeAnnouncement, models.Announcement{Text: "maintenance at 10", SentAt: at.UnixMilli()})},
+		{"server_shutdown", roundTrip(models.MessageTypeServerShutdown, models.ServerShutdown{Seconds: 9.5, At: at.UnixMilli()})},
+		{"replay_control", roundTrip(models.MessageTypeReplayControl, models.ReplayControlRequest{Speed: ptr(2.0), Paused: ptr(false), Seek: ptr(uint64(300))})},
+		{"replay_status", roundTrip(models.MessageTypeReplayStatus, replay.Status{
+			File: "lobby-1.replay", Room: "lobby", StartedAt: at, SimRate: 60, StartTick: 100, EndTick: 900, Tick: 450, Speed: 0.5, Paused: true, Complete: true, Mismatches: 1,
+		})},
+		{"error", roundTrip(models.MessageTypeError, models.ErrorPayload{
+			Code: models.ErrorCodeInvalidPayload, Message: "invalid login: name is required", Type: models.MessageTypeLogin, ID: "7", Field: "name",
+		})},
+	}
+
+	for _, codec := range []Codec{JSONCodec, MsgpackCodec} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) { tt.run(t, codec) })
+			}
+		})
+	}
+}
+
+func TestCodecDecodesWithoutPayload(t *testing.T) {
+	for _, codec := range []Codec{JSONCodec, MsgpackCodec} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			data, err := codec.Encode(models.Message{Type: models.MessageTypeSpectate})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, req, code, err := decodeRequest(codec, data)
+			if err != nil {
+				t.Fatalf("refused with %s: %v", code, err)
+			}
+			if got, ok := req.(*models.SpectateRequest); !ok || *got != (models.SpectateRequest{}) {
+				t.Errorf("got %#v, want an empty *models.SpectateRequest", req)
+			}
+		})
+	}
+}
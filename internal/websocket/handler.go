@@ -1,396 +1,453 @@
 package ws
 
 import (
-	"encoding/json"
+	"fmt"
 	"log"
-	"math"
-	"sync"
+	"net"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gofiber/websocket/v2"
+	"github.com/sangjinsu/websocket-multiplayer/internal/chat"
 	"github.com/sangjinsu/websocket-multiplayer/internal/game"
+	"github.com/sangjinsu/websocket-multiplayer/internal/metrics"
 	"github.com/sangjinsu/websocket-multiplayer/internal/models"
 )
 
+// Options configures per-connection behavior of the handler
+type Options struct {
+	// SendQueueSize is how many outbound frames a connection may have queued
+	SendQueueSize int
+
+	// QueuePolicy decides what happens when that queue is full
+	QueuePolicy QueuePolicy
+
+	// FullSnapshotInterval is how many snapshots may go by before a client
+	// gets a full game_state again instead of a delta
+	FullSnapshotInterval int
+
+	// SimRate is how many fixed physics steps each room runs per second
+	SimRate int
+
+	// SendRate is how many times per second game_state is sent; it can be
+	// lower than SimRate (e.g. 60Hz simulation, 20Hz snapshots)
+	SendRate int
+
+	// Interest limits each client to the players around its own; the zero
+	// value sends everyone the whole room
+	Interest Interest
+
+	// ReconnectGrace is how long a disconnected player stays in the world,
+	// standing still, waiting to be resumed with its token
+	ReconnectGrace time.Duration
+
+	// NoReconnectGrace removes players as soon as their connection drops
+	// instead of keeping them for ReconnectGrace
+	NoReconnectGrace bool
+
+	// Chat checks, filters and keeps chat messages; nil uses a service with
+	// chat.DefaultConfig
+	Chat *chat.Service
+
+	// SpectatorDelay holds back everything spectators receive by this long,
+	// e.g. so a streamed tournament can't be used to cheat. Zero sends it live.
+	SpectatorDelay time.Duration
+
+	// ReplayDir is where replays are recorded and played back from; empty
+	// turns replays off
+	ReplayDir string
+
+	// RecordReplays records every room from the moment it is created until
+	// it closes (needs ReplayDir)
+	RecordReplays bool
+}
+
+// DefaultOptions returns the options used when nothing is configured
+func DefaultOptions() Options {
+	return Options{
+		SendQueueSize:        64,
+		QueuePolicy:          DropStaleState,
+		FullSnapshotInterval: 300,
+		ReconnectGrace:       30 * time.Second,
+		SimRate:              game.TickRate,
+		SendRate:             game.TickRate,
+	}
+}
+
 // Handler represents the websocket handler
 type Handler struct {
-	game *game.Game
-	tickOnce sync.Once
-	lastGameState map[string]*models.Player // 이전 게임 상태 저장
+	rooms *RoomManager
 }
 
 // NewHandler creates a new websocket handler
-func NewHandler(game *game.Game) *Handler {
+func NewHandler(rooms *RoomManager) *Handler {
 	return &Handler{
-		game: game,
+		rooms: rooms,
 	}
 }
 
 // HandleWebSocket handles websocket connections
 func (h *Handler) HandleWebSocket(c *websocket.Conn) {
+	// 종료 중이면 바로 끊음
+	if !h.rooms.enter() {
+		_ = c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+		return
+	}
+	defer h.rooms.exit()
+
+	// 접속할 방 결정 (/ws?room=abc), 없거나 잘못된 경우 기본 방.
+	// /ws?replay=<파일>이면 그 리플레이를 재생하는 방을 관전
+	var room *Room
+	if name := c.Query("replay"); name != "" {
+		var err error
+		if room, err = h.rooms.acquireReplay(name); err != nil {
+			log.Printf("Can't play replay %q: %v", name, err)
+			_ = c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(CloseNoReplay, err.Error()))
+			return
+		}
+	} else {
+		roomID := c.Query("room", DefaultRoomID)
+		if !ValidRoomID(roomID) {
+			log.Printf("Invalid room ID %q, using %s", roomID, DefaultRoomID)
+			roomID = DefaultRoomID
+		}
+		room = h.rooms.Acquire(roomID)
+	}
+
 	// Generate unique player ID
-	playerID := h.game.GenerateID()
-	
+	playerID := room.game.GenerateID()
+
 	// Create temporary player for connection
 	player := &models.Player{
 		ID:       playerID,
 		Conn:     c,
 		LastSeen: time.Now(),
 	}
+	client := newClient(c, negotiateCodec(c), h.rooms.opts)
+	client.player = player
+	client.room = room
+	client.ip = remoteIP(c)
+	room.addClient(client)
 
-	log.Printf("New connection established: %s", playerID)
-
-	// 최초 1회만: 서버 전체에서 60fps 물리 tick 루프 실행
-	h.tickOnce.Do(func() {
-		go func() {
-			ticker := time.NewTicker(16 * time.Millisecond)
-			defer ticker.Stop()
-			for {
-				<-ticker.C
-				h.game.Tick()
-				h.broadcastGameState()
-			}
-		}()
-	})
+	// 이 연결에 대한 모든 쓰기는 writer 고루틴 하나가 담당
+	go client.writePump()
+	metrics.Connections.Inc()
+
+	log.Printf("New connection established: %s (room %s, %s)", playerID, room.ID, client.codec.Name())
+
+	// 리플레이는 관전만 가능
+	if room.playback != nil {
+		h.spectate(client, "")
+	}
 
 	// Handle incoming messages
 	for {
 		_, msg, err := c.ReadMessage()
 		if err != nil {
 			log.Printf("Player %s disconnected: %v", playerID, err)
+			metrics.Disconnects.WithLabelValues(client.disconnectReason(err)).Inc()
 			break
 		}
 
-		var message models.Message
-		if err := json.Unmarshal(msg, &message); err != nil {
-			log.Printf("Error parsing message: %v", err)
+		client.messagesIn.Add(1)
+		client.bytesIn.Add(uint64(len(msg)))
+		metrics.BytesReceived.Add(float64(len(msg)))
+
+		env, payload, code, err := decodeRequest(client.codec, msg)
+		client.handling = Envelope{Type: env.Type, ID: env.ID}
+		if err != nil {
+			client.reject(code, err)
 			continue
 		}
 
-		h.handleMessage(player, message)
+		h.handleMessage(client, payload)
 	}
 
-	// Get player name before removal
-	playerName := ""
-	if player.Name != "" {
-		playerName = player.Name
-	}
+	h.leaveRoom(client)
 
-	// Remove player from game
-	h.game.RemovePlayer(playerID)
+	// 핸들러가 반환되면 conn이 풀로 돌아가므로 writer가 끝날 때까지 기다린다
+	client.Close()
+	<-client.stopped
+	metrics.Connections.Dec()
+}
 
-	// Broadcast player leave
-	h.broadcastPlayerLeave(playerID)
+func (h *Handler) handleMessage(client *Client, payload request) {
+	player := client.player
+	room := client.room
 
-	if playerName != "" {
-		log.Printf("Player %s (%s) left the game", playerName, playerID)
-	} else {
-		log.Printf("Player %s left the game", playerID)
-	}
-}
+	switch p := payload.(type) {
+	case *models.PlayerLogin:
+		if room.isLoggedIn(client) {
+			client.reject(models.ErrorCodeAlreadyLoggedIn, errLoggedIn)
+			return
+		}
+		// A client that still holds a resume token gets its old player
+		// back; an unknown or expired token falls through to a fresh login,
+		// which needs a name
+		if p.ResumeToken != "" && h.resume(client, p.ResumeToken) {
+			return
+		}
+		if strings.TrimSpace(p.Name) == "" {
+			client.reject(models.ErrorCodeInvalidToken, errBadToken)
+			return
+		}
+		if room.playback != nil {
+			client.reject(models.ErrorCodeReplayRoom, ErrReplayRoom)
+			return
+		}
+		if h.rooms.Draining() {
+			client.reject(models.ErrorCodeDraining, errDraining)
+			return
+		}
+
+		log.Printf("Login attempt from player: %s (ID: %s)", p.Name, player.ID)
 
-func (h *Handler) handleMessage(player *models.Player, message models.Message) {
-	switch message.Type {
-	case models.MessageTypeLogin:
-		// Handle player login
-		if payload, ok := message.Payload.(map[string]any); ok {
-			name, _ := payload["name"].(string)
-			color, _ := payload["color"].(string)
-			
-			log.Printf("Login attempt from player: %s (ID: %s)", name, player.ID)
-			
-			// Set player properties
-			player.Name = name
-			if player.Name == "" {
-				player.Name = player.ID // Use ID if name is empty
-			}
-			if color != "" {
-				player.Color = color
-			} else {
-				player.Color = h.game.GetRandomColor()
-			}
-			
-			// Set position
-			if lastPos, ok := payload["lastPosition"].(map[string]any); ok {
-				if x, ok := lastPos["x"].(float64); ok {
-					player.X = x
-				}
-				if y, ok := lastPos["y"].(float64); ok {
-					player.Y = y
-				}
-			} else {
-				// Use random position if no last position
-				player.X, player.Y = h.game.GetRandomPosition()
-			}
-			
-			// Add player to game
-			h.game.AddPlayer(player)
-			
-			// Send welcome message
-			welcomeMsg := models.Message{
-				Type: models.MessageTypeWelcome,
-				Payload: map[string]interface{}{
-					"id":        player.ID,
-					"playerNum": player.PlayerNum,
-					"name":      player.Name,
-					"color":     player.Color,
-				},
-			}
-			h.sendMessage(player.Conn, welcomeMsg)
-			
-			// Broadcast new player to all other players
-			h.broadcastPlayerJoin(player)
-			
-			// Send current game state to new player
-			h.sendGameState(player.Conn)
-			
-			log.Printf("Player %s (%s) joined the game", player.Name, player.ID)
+		world := room.game.Config()
+		if p.LastPosition != nil && !world.Contains(p.LastPosition.X, p.LastPosition.Y) {
+			client.reject(models.ErrorCodeInvalidPayload, invalidPayload(models.MessageTypeLogin, &models.FieldError{
+				Field:  "lastPosition",
+				Reason: fmt.Sprintf("must be inside the %gx%g arena", world.Width, world.Height),
+			}))
+			return
 		}
-		
-	case models.MessageTypeInput:
-		if payload, ok := message.Payload.(map[string]any); ok {
-			// Handle WASD input
-			if key, ok := payload["key"].(string); ok {
-				h.game.ApplyInput(player.ID, key)
-			}
-			
-			// Handle touch/click movement input
-			if vx, ok := payload["vx"].(float64); ok {
-				if vy, ok := payload["vy"].(float64); ok {
-					h.game.ApplyVelocityInput(player.ID, vx, vy)
-				}
-			}
+
+		// Set player properties
+		player.Name = truncateName(p.Name, world.MaxNameLength)
+		if p.Color != "" {
+			player.Color = p.Color
+		} else {
+			player.Color = room.game.GetRandomColor()
 		}
-		return
-		
-	case models.MessageTypeCollision:
-		// Handle player collision
-		if payload, ok := message.Payload.(map[string]any); ok {
-			myID, _ := payload["myId"].(string)
-			partnerID, _ := payload["partnerId"].(string)
-			myNewX, _ := payload["myNewX"].(float64)
-			myNewY, _ := payload["myNewY"].(float64)
-			partnerX, _ := payload["partnerX"].(float64)
-			partnerY, _ := payload["partnerY"].(float64)
-			
-			// Update my position
-			h.game.UpdatePlayerPosition(myID, myNewX, myNewY)
-			
-			// Calculate partner's bounce position (opposite direction)
-			partner := h.game.GetPlayer(partnerID)
-			if partner != nil {
-				// Get collision angle from client or calculate it
-				var collisionAngle float64
-				if angle, ok := payload["collisionAngle"].(float64); ok {
-					collisionAngle = angle
-				} else {
-					// Fallback: calculate angle from positions
-					collisionAngle = math.Atan2(myNewY - partnerY, myNewX - partnerX)
-				}
-				
-				// Partner should move in the opposite direction (add π to angle)
-				oppositeAngle := collisionAngle + math.Pi
-				
-				// Calculate partner's new position in opposite direction
-				partnerNewX := partnerX + math.Cos(oppositeAngle) * 30 // 30 = minDistance
-				partnerNewY := partnerY + math.Sin(oppositeAngle) * 30
-				
-				// Keep partner position within bounds
-				if partnerNewX < 15 {
-					partnerNewX = 15
-				} else if partnerNewX > 785 {
-					partnerNewX = 785
-				}
-				if partnerNewY < 15 {
-					partnerNewY = 15
-				} else if partnerNewY > 585 {
-					partnerNewY = 585
-				}
-				
-				// Update partner position
-				h.game.UpdatePlayerPosition(partnerID, partnerNewX, partnerNewY)
-				
-				// Broadcast both movements
-				h.broadcastPlayerMove(player)
-				h.broadcastPlayerMove(partner)
-				
-				log.Printf("Collision between %s and %s - opposite bounce applied", 
-					player.Name, partner.Name)
-			}
+
+		// Set position (a saved position keeps the player's body off the walls)
+		if p.LastPosition != nil {
+			player.X, player.Y = world.Clamp(p.LastPosition.X, p.LastPosition.Y)
+		} else {
+			// Use random position if no last position
+			player.X, player.Y = room.game.GetRandomPosition()
+		}
+
+		h.joinWorld(client)
+
+	case *models.JoinRoomRequest:
+		// Move the connection to another room
+		if !ValidRoomID(p.Room) {
+			client.reject(models.ErrorCodeInvalidPayload, invalidPayload(models.MessageTypeJoinRoom,
+				&models.FieldError{Field: "room", Reason: "must be 1-32 letters, digits, '-' or '_'"}))
+			return
 		}
-		
-	case models.MessageTypeReconnect:
-		// Handle player reconnection with existing ID
-		if payload, ok := message.Payload.(map[string]interface{}); ok {
-			if id, ok := payload["id"].(string); ok {
-				// Check if player ID already exists
-				if existingPlayer := h.game.GetPlayer(id); existingPlayer != nil {
-					// Update existing player's connection
-					existingPlayer.Conn = player.Conn
-					existingPlayer.LastSeen = time.Now()
-					
-					// Remove the new player and use existing one
-					h.game.RemovePlayer(player.ID)
-					
-					// Send welcome message with existing info
-					welcomeMsg := models.Message{
-						Type: models.MessageTypeWelcome,
-						Payload: map[string]interface{}{
-							"id":    existingPlayer.ID,
-							"color": existingPlayer.Color,
-						},
-					}
-					h.sendMessage(player.Conn, welcomeMsg)
-					
-					// Send current game state
-					h.sendGameState(player.Conn)
-					
-					log.Printf("Player %s reconnected", id)
-					return
-				}
-			}
+		h.switchRoom(client, p.Room)
+
+	case *models.StateAck:
+		// Client confirms the snapshot it applied; deltas are built against it
+		client.ack(p.Seq)
+
+	case *models.InputRequest:
+		// p.Seq is echoed back as the player's lastInputSeq
+		var err error
+		if p.Key != "" {
+			err = room.game.ApplyInput(player.ID, p.Key, p.Seq)
+		}
+		// Touch/click movement input
+		if p.HasVelocity() && err == nil {
+			err = room.game.ApplyVelocityInput(player.ID, *p.Vx, *p.Vy, p.Seq)
+		}
+		if err != nil {
+			client.reject(models.ErrorCodeNotLoggedIn, err)
+		}
+
+	case *models.SpectateRequest:
+		// Watch the room without joining the world
+		h.spectate(client, p.Follow)
+
+	case *models.ReplayControlRequest:
+		// Speed, pause and seek of the replay the room plays
+		if room.playback == nil {
+			client.reject(models.ErrorCodeNotReplay, errNotReplay)
+			return
+		}
+		h.controlReplay(client, p)
+
+	case *models.ChatRequest:
+		if !room.isLoggedIn(client) {
+			client.reject(models.ErrorCodeNotLoggedIn, errNotLoggedIn)
+			return
+		}
+		h.chat(client, p.Channel, p.To, p.Text)
+
+	case *models.ReconnectRequest:
+		// Take back the player behind a resume token (see welcome.resumeToken)
+		if room.isLoggedIn(client) {
+			client.reject(models.ErrorCodeAlreadyLoggedIn, errLoggedIn)
+			return
+		}
+		if !h.resume(client, p.Token) {
+			client.reject(models.ErrorCodeInvalidToken, errBadToken)
 		}
 	}
 }
 
-func (h *Handler) broadcastPlayerJoin(player *models.Player) {
-	msg := models.Message{
-		Type: models.MessageTypePlayerJoin,
-		Payload: map[string]interface{}{
-			"id":        player.ID,
-			"playerNum": player.PlayerNum,
-			"name":      player.Name,
-			"x":         player.X,
-			"y":         player.Y,
-			"color":     player.Color,
-		},
+// joinWorld adds the client's player to its room and announces it
+func (h *Handler) joinWorld(client *Client) {
+	player := client.player
+	room := client.room
+
+	// Add player to game; the next broadcast sends it a full snapshot
+	if err := room.game.AddPlayer(player); err != nil {
+		client.reject(models.ErrorCodeRoomFull, fmt.Errorf("can't join room %s: %w", room.ID, err))
+		return
 	}
+	client.requestFullState()
 
-	// 원본 State.Players에서 Conn이 있는 플레이어들에게만 전송
-	h.game.State.Mu.RLock()
-	defer h.game.State.Mu.RUnlock()
-	for _, p := range h.game.State.Players {
-		if p.ID != player.ID && p.Conn != nil {
-			h.sendMessage(p.Conn, msg)
-		}
+	// Send welcome message with a fresh resume token
+	token := h.rooms.openSession(client)
+	client.Send(welcomeMessage(room, player, token, false))
+	room.setLoggedIn(client, true)
+	metrics.Logins.WithLabelValues("new").Inc()
+	room.sendRound(client)
+	h.sendChatHistory(client)
+
+	// Broadcast new player to all other players
+	if state, ok := room.game.PlayerState(player.ID); ok {
+		room.broadcastPlayerJoin(state)
 	}
+
+	log.Printf("Player %s (%s) joined room %s", player.Name, player.ID, room.ID)
 }
 
-func (h *Handler) broadcastPlayerLeave(playerID string) {
-	msg := models.Message{
-		Type: models.MessageTypePlayerLeave,
-		Payload: map[string]string{
-			"id": playerID,
-		},
+// resume moves the client onto the player behind a resume token, in whatever
+// room that player is, and reports whether the token was accepted
+func (h *Handler) resume(client *Client, token string) bool {
+	if token == "" || client.room.isLoggedIn(client) {
+		return false
+	}
+	room, player, token, ok := h.rooms.resume(token, client)
+	if !ok {
+		return false
 	}
 
-	// 원본 State.Players에서 Conn이 있는 플레이어들에게만 전송
-	h.game.State.Mu.RLock()
-	defer h.game.State.Mu.RUnlock()
-	for _, p := range h.game.State.Players {
-		if p.Conn != nil {
-			h.sendMessage(p.Conn, msg)
-		}
+	// resume이 새 방 참조를 넘겨주므로 지금 방 참조는 반납
+	if room != client.room {
+		h.leaveRoom(client)
+		client.room = room
+		room.addClient(client)
+	} else {
+		h.rooms.Release(room)
 	}
+
+	player.Conn = client.conn
+	client.requestFullState()
+	client.Send(welcomeMessage(room, player, token, true))
+	room.attachPlayer(client, player)
+	room.sendRound(client)
+	h.sendChatHistory(client)
+	h.rooms.warnShutdown(client)
+	metrics.Logins.WithLabelValues("resume").Inc()
+
+	log.Printf("Player %s (%s) resumed in room %s", player.Name, player.ID, room.ID)
+	return true
 }
 
-func (h *Handler) broadcastPlayerMove(player *models.Player) {
-	msg := models.Message{
-		Type: models.MessageTypePlayerMove,
-		Payload: map[string]any{
-			"id": player.ID,
-			"x":  player.X,
-			"y":  player.Y,
+// welcomeMessage builds the welcome sent after a login or resume
+func welcomeMessage(room *Room, player *models.Player, token string, resumed bool) models.Message {
+	return models.Message{
+		Type: models.MessageTypeWelcome,
+		Payload: models.WelcomePayload{
+			ID:          player.ID,
+			PlayerNum:   player.PlayerNum,
+			Name:        player.Name,
+			Color:       player.Color,
+			Room:        room.ID,
+			ResumeToken: token,
+			Resumed:     resumed,
+			World:       room.game.Config(),
 		},
 	}
-
-	// 원본 State.Players에서 Conn이 있는 플레이어들에게만 전송
-	h.game.State.Mu.RLock()
-	defer h.game.State.Mu.RUnlock()
-	for _, p := range h.game.State.Players {
-		if p.ID != player.ID && p.Conn != nil {
-			h.sendMessage(p.Conn, msg)
-		}
-	}
 }
 
-func (h *Handler) sendGameState(conn *websocket.Conn) {
-	players := h.game.GetAllPlayers()
-	
-	// Create a copy without websocket connections for JSON serialization
-	playersCopy := make(map[string]*models.Player)
-	for id, player := range players {
-		playersCopy[id] = &models.Player{
-			ID:        player.ID,
-			PlayerNum: player.PlayerNum,
-			Name:      player.Name,
-			X:         player.X,
-			Y:         player.Y,
-			Color:     player.Color,
-			JoinedAt:  player.JoinedAt,
-			LastSeen:  player.LastSeen,
-		}
+// leaveWorld takes the client's player out of its room, if it was ever
+// added. When the connection dropped (disconnected) the player may instead
+// be kept for the reconnect grace period; otherwise the player is moving to
+// another room and keeps its chat rate limit.
+func (h *Handler) leaveWorld(client *Client, disconnected bool) {
+	room := client.room
+	if !room.logout(client) {
+		return
 	}
+	player := client.player
 
-	msg := models.Message{
-		Type:    models.MessageTypeGameState,
-		Payload: playersCopy,
+	if disconnected && h.rooms.detach(client) {
+		log.Printf("Player %s (%s) disconnected from room %s, waiting for reconnect", player.Name, player.ID, room.ID)
+		return
 	}
+	h.rooms.closeSession(client)
+	if disconnected {
+		h.rooms.opts.Chat.Forget(player.ID)
+	}
+
+	// Remove player from game
+	room.game.RemovePlayer(player.ID)
+
+	// Broadcast player leave
+	room.broadcastPlayerLeave(player.ID)
+
+	log.Printf("Player %s (%s) left room %s", player.Name, player.ID, room.ID)
+}
 
-	h.sendMessage(conn, msg)
+// leaveRoom takes the client out of its room for good (connection closed)
+func (h *Handler) leaveRoom(client *Client) {
+	h.leaveWorld(client, true)
+	client.room.removeClient(client)
+	h.rooms.Release(client.room)
 }
 
-func (h *Handler) sendMessage(conn *websocket.Conn, message models.Message) {
-	data, err := json.Marshal(message)
-	if err != nil {
-		log.Printf("Error marshaling message: %v", err)
+// switchRoom moves the client to another room, carrying its player over if
+// it has already logged in
+func (h *Handler) switchRoom(client *Client, roomID string) {
+	if client.room.ID == roomID {
 		return
 	}
 
-	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		log.Printf("Error sending message: %v", err)
-	}
-} 
-
-// 모든 플레이어에게 현재 상태 브로드캐스트 (변경사항이 있을 때만)
-func (h *Handler) broadcastGameState() {
-	players := h.game.GetAllPlayers()
-	
-	// 현재 상태를 JSON으로 직렬화하여 변경사항 확인
-	currentState, err := json.Marshal(players)
-	if err != nil {
-		log.Printf("Error marshaling current game state: %v", err)
+	wasLoggedIn := client.room.isLoggedIn(client)
+	wasSpectator := client.room.isSpectator(client)
+	next := h.rooms.Acquire(roomID)
+	if wasLoggedIn && next.game.Full() {
+		client.reject(models.ErrorCodeRoomFull, fmt.Errorf("can't move to room %s: %w", roomID, game.ErrGameFull))
+		h.rooms.Release(next)
 		return
 	}
-	
-	// 이전 상태와 비교하여 변경사항이 있는지 확인
-	if h.lastGameState != nil {
-		lastState, _ := json.Marshal(h.lastGameState)
-		if string(currentState) == string(lastState) {
-			return // 변경사항이 없으면 브로드캐스트하지 않음
-		}
+	h.leaveWorld(client, false)
+	h.leaveRoom(client)
+	client.room = next
+	next.addClient(client)
+
+	if wasLoggedIn {
+		client.player.X, client.player.Y = next.game.GetRandomPosition()
+		client.player.Vx, client.player.Vy = 0, 0
+		h.joinWorld(client)
 	}
-	
-	// 변경사항이 있으면 브로드캐스트
-	msg := models.Message{
-		Type:    models.MessageTypeGameState,
-		Payload: players,
+	if wasSpectator {
+		h.spectate(client, "")
 	}
-	data, err := json.Marshal(msg)
-	if err != nil {
-		log.Printf("Error marshaling game state: %v", err)
-		return
+}
+
+// remoteIP returns the client address the upgrade handler stored in the "ip"
+// local (see main), or the socket's peer address
+func remoteIP(c *websocket.Conn) string {
+	if ip, ok := c.Locals("ip").(string); ok && ip != "" {
+		return ip
 	}
-	
-	// 원본 State.Players에서 Conn이 있는 플레이어들에게만 전송
-	h.game.State.Mu.RLock()
-	defer h.game.State.Mu.RUnlock()
-	for _, p := range h.game.State.Players {
-		if p.Conn != nil {
-			_ = p.Conn.WriteMessage(websocket.TextMessage, data)
-		}
+	if host, _, err := net.SplitHostPort(c.RemoteAddr().String()); err == nil {
+		return host
+	}
+	return c.RemoteAddr().String()
+}
+
+// truncateName shortens name to at most limit characters (0 = no limit)
+func truncateName(name string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(name) <= limit {
+		return name
 	}
-	
-	// 현재 상태를 이전 상태로 저장
-	h.lastGameState = players
-} 
\ No newline at end of file
+	return string([]rune(name)[:limit])
+}
@@ -0,0 +1,188 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/chat"
+	"github.com/sangjinsu/websocket-multiplayer/internal/game"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+func newTestHandler(opts Options) *Handler {
+	return NewHandler(NewRoomManager(func() *game.Game {
+		return game.NewGame(models.DefaultWorldConfig())
+	}, opts))
+}
+
+// connect opens a connection without a socket in roomID, the way
+// HandleWebSocket does; close it with h.leaveRoom
+func (h *Handler) connect(roomID string) *Client {
+	room := h.rooms.Acquire(roomID)
+	client := newClient(nil, JSONCodec, h.rooms.opts)
+	client.player = &models.Player{ID: room.game.GenerateID()}
+	client.room = room
+	client.ip = "192.0.2.1"
+	room.addClient(client)
+	return client
+}
+
+// login logs the client in and fails the test if the server refused it
+func (h *Handler) login(t *testing.T, client *Client, name string) {
+	t.Helper()
+	h.handleMessage(client, &models.PlayerLogin{Name: name})
+	if !client.room.isLoggedIn(client) {
+		t.Fatalf("login as %s refused: %v", name, errorCodes(t, client))
+	}
+}
+
+// errorCodes returns the codes of the error messages queued for client
+func errorCodes(t *testing.T, client *Client) []models.ErrorCode {
+	t.Helper()
+	var codes []models.ErrorCode
+	for _, env := range received(t, client) {
+		if env.Type == models.MessageTypeError {
+			codes = append(codes, decodePayload[models.ErrorPayload](t, client, env).Code)
+		}
+	}
+	return codes
+}
+
+// 한 번 보내면 사실상 다시 채워지지 않는 채팅 제한
+func strictChat() *chat.Service {
+	return chat.New(chat.Config{MaxLength: 200, Rate: 0.001, Burst: 1})
+}
+
+func TestSwitchRoomKeepsChatLimit(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Chat = strictChat()
+	h := newTestHandler(opts)
+	client := h.connect("a")
+	defer h.leaveRoom(client)
+	h.login(t, client, "hopper")
+
+	h.chat(client, models.ChatRoom, "", "hello a")
+	h.switchRoom(client, "b")
+	received(t, client)
+	h.chat(client, models.ChatRoom, "", "hello b")
+
+	codes := errorCodes(t, client)
+	if len(codes) != 1 || codes[0] != models.ErrorCodeRateLimited {
+		t.Errorf("chat after join_room got errors %v, want [rate_limited]", codes)
+	}
+}
+
+func TestDisconnectForgetsChatLimit(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Chat = strictChat()
+	opts.NoReconnectGrace = true
+	h := newTestHandler(opts)
+	client := h.connect("a")
+	h.login(t, client, "leaver")
+	player := client.player
+
+	h.chat(client, models.ChatRoom, "", "bye")
+	h.leaveRoom(client)
+
+	if _, err := opts.Chat.Accept(player, "a", models.ChatRoom, "", "back"); err != nil {
+		t.Errorf("chat limit kept after the session ended: %v", err)
+	}
+}
+
+func TestGraceExpiryForgetsChatLimit(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Chat = strictChat()
+	opts.ReconnectGrace = 200 * time.Millisecond
+	h := newTestHandler(opts)
+	client := h.connect("a")
+	h.login(t, client, "sleeper")
+	player, room := client.player, client.room
+
+	h.chat(client, models.ChatRoom, "", "brb")
+	h.leaveRoom(client)
+
+	// 유예 중에는 같은 플레이어이므로 제한이 남아 있음
+	if _, err := opts.Chat.Accept(player, "a", models.ChatRoom, "", "still here?"); err != chat.ErrRateLimited {
+		t.Fatalf("chat during the grace period: %v, want %v", err, chat.ErrRateLimited)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := room.game.PlayerState(player.ID); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("player was not removed after the grace period")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := opts.Chat.Accept(player, "a", models.ChatRoom, "", "back"); err != nil {
+		t.Errorf("chat limit kept after the grace period ran out: %v", err)
+	}
+}
+
+func TestLoginRejectsLastPositionOutsideArena(t *testing.T) {
+	h := newTestHandler(DefaultOptions())
+	world := models.DefaultWorldConfig()
+	tests := []struct {
+		name string
+		pos  models.Point
+		ok   bool
+	}{
+		{"inside", models.Point{X: world.Width / 2, Y: world.Height / 2}, true},
+		{"on the wall", models.Point{X: 0, Y: world.Height}, true},
+		{"left of the arena", models.Point{X: -1, Y: 100}, false},
+		{"below the arena", models.Point{X: 100, Y: world.Height + 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := h.connect("a")
+			defer h.leaveRoom(client)
+			client.handling = Envelope{Type: models.MessageTypeLogin, ID: "1"}
+
+			pos := tt.pos
+			h.handleMessage(client, &models.PlayerLogin{Name: "p", LastPosition: &pos})
+			if got := client.room.isLoggedIn(client); got != tt.ok {
+				t.Fatalf("logged in = %v, want %v", got, tt.ok)
+			}
+			if tt.ok {
+				if x, y := client.player.X, client.player.Y; !world.Contains(x, y) || x < world.PlayerRadius || y > world.Height-world.PlayerRadius {
+					t.Errorf("player placed at (%g, %g), want its body inside the arena", x, y)
+				}
+				return
+			}
+			for _, env := range received(t, client) {
+				if env.Type != models.MessageTypeError {
+					continue
+				}
+				e := decodePayload[models.ErrorPayload](t, client, env)
+				if e.Code != models.ErrorCodeInvalidPayload || e.Field != "lastPosition" || e.ID != "1" {
+					t.Errorf("got error %+v, want invalid_payload on lastPosition for request 1", e)
+				}
+				return
+			}
+			t.Error("no error sent")
+		})
+	}
+}
+
+func TestSpectateReply(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SpectatorDelay = 1500 * time.Millisecond
+	h := newTestHandler(opts)
+	client := h.connect("a")
+	defer h.leaveRoom(client)
+
+	h.spectate(client, "p1")
+	for _, env := range received(t, client) {
+		if env.Type != models.MessageTypeSpectate {
+			continue
+		}
+		got := decodePayload[models.SpectatePayload](t, client, env)
+		if got.Room != "a" || got.Follow != "p1" || got.Delay != 1500 || got.World.Width == 0 {
+			t.Errorf("spectate reply %+v, want room a following p1 with a 1500ms delay", got)
+		}
+		return
+	}
+	t.Error("no spectate reply")
+}
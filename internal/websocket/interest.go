@@ -0,0 +1,198 @@
+package ws
+
+import (
+	"math"
+	"slices"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// Interest is the area around a player that its connection gets updates
+// for. With Radius set it is a circle; otherwise it is a Width x Height
+// rectangle centered on the player, e.g. the client's viewport. The zero
+// value turns interest management off and every client sees the whole room.
+type Interest struct {
+	Radius float64
+	Width  float64
+	Height float64
+}
+
+// 이미 보이는 플레이어는 경계를 조금 넘어도 유지 (경계에서 enter/exit가 반복되지 않도록)
+const interestExitMargin = 1.1
+
+// Enabled reports whether interest management is on
+func (a Interest) Enabled() bool {
+	return a.Radius > 0 || (a.Width > 0 && a.Height > 0)
+}
+
+// contains reports whether other is inside the area around center, with the
+// area grown by scale
+func (a Interest) contains(center, other models.PlayerState, scale float64) bool {
+	dx := other.X - center.X
+	dy := other.Y - center.Y
+	if a.Radius > 0 {
+		r := a.Radius * scale
+		return dx*dx+dy*dy <= r*r
+	}
+	return math.Abs(dx) <= a.Width/2*scale && math.Abs(dy) <= a.Height/2*scale
+}
+
+// playerSet is a set of player IDs
+type playerSet map[string]struct{}
+
+// interestView is what one connection currently sees, plus what it saw at
+// each recent snapshot so deltas can be built against its own baseline.
+// Only the tick loop uses it.
+type interestView struct {
+	visible playerSet
+	seen    [snapshotHistorySize]seenPlayers
+}
+
+type seenPlayers struct {
+	seq uint32
+	ids playerSet
+}
+
+// at returns the players the client saw at snapshot seq, or nil if that
+// snapshot is no longer kept
+func (v *interestView) at(seq uint32) playerSet {
+	s := v.seen[seq%snapshotHistorySize]
+	if s.seq != seq {
+		return nil
+	}
+	return s.ids
+}
+
+// filterPlayers returns the states of the players in ids
+func filterPlayers(players map[string]models.PlayerState, ids playerSet) map[string]models.PlayerState {
+	filtered := make(map[string]models.PlayerState, len(ids))
+	for id := range ids {
+		if p, ok := players[id]; ok {
+			filtered[id] = p
+		}
+	}
+	return filtered
+}
+
+// sendInterestState sends the client its own view of snap: only the players
+// inside its interest area, preceded by player_enter/player_exit for players
+// that crossed the boundary since the last snapshot, and player_join /
+// player_leave for ones that joined or left the room inside it. prev is the
+// previous snapshot, if any. The caller must hold r.mu.
+func (r *Room) sendInterestState(client *Client, snap, prev *snapshot) {
+	self, ok := snap.players[client.player.ID]
+	if !ok {
+		return
+	}
+	// 로그인/재접속/방 이동 직후에는 전체 스냅샷이 가므로 이벤트 없이 시야를 새로 계산
+	fresh := client.view == nil || client.needFull.Load()
+	if fresh {
+		client.view = &interestView{}
+	}
+	view := client.view
+
+	visible := playerSet{self.ID: {}}
+	for id, p := range snap.players {
+		scale := 1.0
+		if _, was := view.visible[id]; was {
+			scale = interestExitMargin
+		}
+		if r.opts.Interest.contains(self, p, scale) {
+			visible[id] = struct{}{}
+		}
+	}
+
+	if !fresh {
+		r.sendInterestEvents(client, view.visible, visible, snap, prev)
+	}
+	view.visible = visible
+	view.seen[snap.seq%snapshotHistorySize] = seenPlayers{seq: snap.seq, ids: visible}
+
+	players := filterPlayers(snap.players, visible)
+	payload := snap.envelope()
+
+	base := r.history.get(client.ackedSeq.Load())
+	var baseIDs playerSet
+	if base != nil {
+		baseIDs = view.at(base.seq)
+	}
+	if baseIDs == nil || client.needFull.Load() || snap.seq-client.lastFullSeq >= uint32(r.opts.FullSnapshotInterval) {
+		if client.needFull.Swap(false) {
+			client.minAck.Store(snap.seq)
+		}
+		client.lastFullSeq = snap.seq
+		payload.Full = true
+		payload.Players = players
+	} else {
+		payload.Baseline = base.seq
+		if !diffPlayers(&payload, filterPlayers(base.players, baseIDs), players) {
+			return
+		}
+	}
+
+	msg := stateMessage(payload)
+	data, err := client.codec.Encode(msg)
+	if err != nil {
+		logEncodeError(client.codec, msg, err)
+		return
+	}
+	client.sendState(data)
+}
+
+// sendInterestEvents tells the client about players that appeared in or
+// disappeared from its view
+func (r *Room) sendInterestEvents(client *Client, before, after playerSet, snap, prev *snapshot) {
+	var entered, exited []string
+	for id := range after {
+		if _, ok := before[id]; !ok {
+			entered = append(entered, id)
+		}
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			exited = append(exited, id)
+		}
+	}
+	slices.Sort(entered)
+	slices.Sort(exited)
+
+	for _, id := range entered {
+		msgType := models.MessageTypePlayerEnter
+		if prev == nil {
+			msgType = models.MessageTypePlayerJoin
+		} else if _, existed := prev.players[id]; !existed {
+			msgType = models.MessageTypePlayerJoin
+		}
+		client.Send(models.Message{Type: msgType, Payload: playerPayload(snap.players[id])})
+	}
+	for _, id := range exited {
+		msgType := models.MessageTypePlayerExit
+		if _, exists := snap.players[id]; !exists {
+			msgType = models.MessageTypePlayerLeave
+		}
+		client.Send(models.Message{Type: msgType, Payload: models.PlayerLeave{ID: id}})
+	}
+}
+
+// broadcastInterestCollisions sends each client only the collisions that
+// involve a player it can see
+func (r *Room) broadcastInterestCollisions(events []models.CollisionEvent) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for client := range r.clients {
+		if !client.loggedIn || client.view == nil {
+			continue
+		}
+		var seen []models.CollisionEvent
+		for _, ev := range events {
+			_, a := client.view.visible[ev.A]
+			_, b := client.view.visible[ev.B]
+			if a || b {
+				seen = append(seen, ev)
+			}
+		}
+		if len(seen) > 0 {
+			client.Send(collisionMessage(seen))
+		}
+	}
+}
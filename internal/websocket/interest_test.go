@@ -0,0 +1,225 @@
+package ws
+
+import (
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+func interestRoom(radius float64) *Room {
+	opts := DefaultOptions()
+	opts.Interest = Interest{Radius: radius}
+	return newTestRoom(opts)
+}
+
+// interestStep pushes a snapshot of players and sends c its view of it
+func interestStep(r *Room, c *Client, players ...models.PlayerState) {
+	snap, prev := r.pushTestSnapshot(players...)
+	r.sendInterestState(c, snap, prev)
+}
+
+// events returns the enter/exit/join/leave messages in envs as "type id"
+func events(t *testing.T, c *Client, envs []Envelope) []string {
+	t.Helper()
+	var out []string
+	for _, env := range envs {
+		switch env.Type {
+		case models.MessageTypePlayerJoin, models.MessageTypePlayerEnter:
+			out = append(out, string(env.Type)+" "+decodePayload[models.PlayerJoin](t, c, env).ID)
+		case models.MessageTypePlayerLeave, models.MessageTypePlayerExit:
+			out = append(out, string(env.Type)+" "+decodePayload[models.PlayerLeave](t, c, env).ID)
+		}
+	}
+	return out
+}
+
+func TestInterestContains(t *testing.T) {
+	self := player("a", 100, 100)
+	tests := []struct {
+		name  string
+		area  Interest
+		other models.PlayerState
+		scale float64
+		want  bool
+	}{
+		{"inside radius", Interest{Radius: 50}, player("b", 130, 140), 1, true},
+		{"on radius", Interest{Radius: 50}, player("b", 150, 100), 1, true},
+		{"outside radius", Interest{Radius: 50}, player("b", 136, 136), 1, false},
+		{"inside grown radius", Interest{Radius: 50}, player("b", 154, 100), interestExitMargin, true},
+		{"inside rectangle", Interest{Width: 200, Height: 100}, player("b", 199, 149), 1, true},
+		{"outside rectangle height", Interest{Width: 200, Height: 100}, player("b", 100, 151), 1, false},
+		{"radius wins over rectangle", Interest{Radius: 10, Width: 200, Height: 200}, player("b", 150, 100), 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.area.contains(self, tt.other, tt.scale); got != tt.want {
+				t.Errorf("contains = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterestHysteresis(t *testing.T) {
+	r := interestRoom(100)
+	c := newTestClient(r, "a")
+
+	// 경계 안 -> 1.1배 안쪽 -> 1.1배 밖 -> 다시 1.1배 안쪽 -> 경계 안
+	steps := []struct {
+		bx   float64
+		want []string
+	}{
+		{90, nil}, // 첫 스냅샷은 전체 상태만
+		{105, nil},
+		{109, nil},
+		{115, []string{"player_exit b"}},
+		{105, nil},
+		{95, []string{"player_enter b"}},
+		{108, nil},
+	}
+	for i, s := range steps {
+		interestStep(r, c, player("a", 0, 0), player("b", s.bx, 0))
+		if got := events(t, c, received(t, c)); !slices.Equal(got, s.want) {
+			t.Errorf("step %d (b at x=%g): events %v, want %v", i, s.bx, got, s.want)
+		}
+	}
+}
+
+func TestInterestJoinLeaveEnterExit(t *testing.T) {
+	r := interestRoom(100)
+	c := newTestClient(r, "a")
+	a := player("a", 0, 0)
+
+	steps := []struct {
+		name    string
+		players []models.PlayerState
+		want    []string
+	}{
+		{"only self", []models.PlayerState{a}, nil},
+		{"b joins inside, c joins outside", []models.PlayerState{a, player("b", 50, 0), player("c", 500, 0)}, []string{"player_join b"}},
+		{"c walks in, b walks out", []models.PlayerState{a, player("b", 500, 0), player("c", 50, 0)}, []string{"player_enter c", "player_exit b"}},
+		{"b leaves outside", []models.PlayerState{a, player("c", 50, 0)}, nil},
+		{"c leaves inside", []models.PlayerState{a}, []string{"player_leave c"}},
+	}
+	for _, s := range steps {
+		interestStep(r, c, s.players...)
+		if got := events(t, c, received(t, c)); !slices.Equal(got, s.want) {
+			t.Errorf("%s: events %v, want %v", s.name, got, s.want)
+		}
+	}
+}
+
+func TestInterestFreshViewSendsNoEvents(t *testing.T) {
+	r := interestRoom(100)
+	c := newTestClient(r, "a")
+	interestStep(r, c, player("a", 0, 0))
+	received(t, c)
+
+	// 방 이동/재접속 뒤에는 전체 스냅샷만 (이미 있던 b를 join으로 알리지 않음)
+	c.requestFullState()
+	interestStep(r, c, player("a", 0, 0), player("b", 10, 0))
+	envs := received(t, c)
+	if got := events(t, c, envs); got != nil {
+		t.Errorf("events after requestFullState: %v, want none", got)
+	}
+	if len(envs) != 1 || !decodePayload[models.GameStatePayload](t, c, envs[0]).Full {
+		t.Errorf("want one full game_state, got %d messages", len(envs))
+	}
+}
+
+// 클라이언트마다 자기가 ack한 스냅샷에서 보던 플레이어를 기준으로 델타를 만들어야
+// 적용한 결과가 지금 보이는 플레이어와 같아진다
+func TestInterestDeltasAgainstOwnBaseline(t *testing.T) {
+	r := interestRoom(100)
+	c := newTestClient(r, "a")
+	m := newMirror()
+
+	sendAndApply := func(players ...models.PlayerState) models.GameStatePayload {
+		t.Helper()
+		interestStep(r, c, players...)
+		var state models.GameStatePayload
+		for _, env := range received(t, c) {
+			if env.Type == models.MessageTypeGameState {
+				state = decodePayload[models.GameStatePayload](t, c, env)
+				m.apply(t, state)
+			}
+		}
+		return state
+	}
+
+	first := sendAndApply(player("a", 0, 0), player("b", 50, 0), player("c", 300, 0))
+	c.ack(first.Seq)
+
+	// ack 없이 두 번: b가 나가고 c가 들어옴, c가 움직임
+	sendAndApply(player("a", 0, 0), player("b", 300, 0), player("c", 60, 0))
+	last := sendAndApply(player("a", 0, 0), player("b", 300, 0), player("c", 70, 0))
+
+	if last.Full || last.Baseline != first.Seq {
+		t.Fatalf("want a delta against acked %d, got full=%v baseline=%d", first.Seq, last.Full, last.Baseline)
+	}
+	if !slices.Equal(last.Removed, []string{"b"}) {
+		t.Errorf("removed %v, want [b]", last.Removed)
+	}
+	if d, ok := last.Changed["c"]; !ok || d.Name == nil {
+		t.Errorf("c entered after the baseline and must be sent in full, got %+v", last.Changed)
+	}
+	want := map[string]models.PlayerState{"a": player("a", 0, 0), "c": player("c", 70, 0)}
+	if got := m.current(); !maps.Equal(got, want) {
+		t.Errorf("client state %v, want %v", got, want)
+	}
+}
+
+func TestInterestRandomWalkMatchesView(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	r := interestRoom(120)
+	ids := []string{"a", "b", "c", "d", "e", "f"}
+	clients := map[string]*Client{}
+	mirrors := map[string]*mirror{}
+	for _, id := range ids[:3] {
+		clients[id] = newTestClient(r, id)
+		mirrors[id] = newMirror()
+	}
+
+	pos := map[string]models.PlayerState{}
+	for _, id := range ids {
+		pos[id] = player(id, rng.Float64()*400, rng.Float64()*400)
+	}
+
+	for tick := 0; tick < 2*snapshotHistorySize; tick++ {
+		players := make([]models.PlayerState, 0, len(ids))
+		for _, id := range ids {
+			p := pos[id]
+			p.X += rng.Float64()*40 - 20
+			p.Y += rng.Float64()*40 - 20
+			pos[id] = p
+			// 연결 없는 플레이어(d, e, f)는 가끔 방을 나갔다가 돌아옴
+			if id >= "d" && rng.IntN(10) == 0 {
+				continue
+			}
+			players = append(players, p)
+		}
+
+		snap, prev := r.pushTestSnapshot(players...)
+		for _, id := range ids[:3] {
+			c := clients[id]
+			r.sendInterestState(c, snap, prev)
+			for _, env := range received(t, c) {
+				if env.Type != models.MessageTypeGameState {
+					continue
+				}
+				state := decodePayload[models.GameStatePayload](t, c, env)
+				mirrors[id].apply(t, state)
+				// ack는 불규칙하게, 가끔은 아주 늦게
+				if rng.IntN(3) == 0 {
+					c.ack(state.Seq)
+				}
+			}
+			want := filterPlayers(snap.players, c.view.visible)
+			if got := mirrors[id].current(); !maps.Equal(got, want) {
+				t.Fatalf("tick %d: client %s has %v, want %v", tick, id, got, want)
+			}
+		}
+	}
+}
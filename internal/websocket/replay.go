@@ -0,0 +1,194 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+	"github.com/sangjinsu/websocket-multiplayer/internal/replay"
+)
+
+// 리플레이 녹화와 재생 (/ws?replay=<파일>)
+
+// 녹화/재생을 할 수 없는 이유
+var (
+	ErrReplaysDisabled = errors.New("replays are disabled (set replayDir)")
+	ErrReplayNotFound  = errors.New("replay not found")
+	ErrRecording       = errors.New("room is already being recorded")
+	ErrNotRecording    = errors.New("room is not being recorded")
+	ErrReplayRoom      = errors.New("room is playing a replay")
+)
+
+// 재생 방 ID 앞에 붙는 접두사. ':'는 방 ID에 쓸 수 없어 일반 방과 겹치지 않음.
+const replayRoomPrefix = "replay:"
+
+// 재생 중인 방이 관전자에게 replay_status를 보내는 간격
+const replayStatusInterval = time.Second
+
+// StartRecording starts recording the room into a new file in the replay
+// directory and returns the file name
+func (r *Room) StartRecording() (string, error) {
+	if r.opts.ReplayDir == "" {
+		return "", ErrReplaysDisabled
+	}
+	if r.playback != nil {
+		return "", ErrReplayRoom
+	}
+	r.recMu.Lock()
+	defer r.recMu.Unlock()
+	if r.recorder != nil {
+		return "", ErrRecording
+	}
+
+	// 방이 닫혔다 바로 다시 열려도 겹치지 않도록 밀리초까지
+	name := r.ID + "-" + time.Now().Format("20060102-150405.000") + replay.Ext
+	rec, err := replay.Start(r.game, filepath.Join(r.opts.ReplayDir, name), r.ID, r.opts.SimRate)
+	if err != nil {
+		return "", err
+	}
+	r.recorder = rec
+	log.Printf("Recording room %s into %s", r.ID, name)
+	return name, nil
+}
+
+// StopRecording ends the room's recording and returns the file name
+func (r *Room) StopRecording() (string, error) {
+	r.recMu.Lock()
+	defer r.recMu.Unlock()
+	if r.recorder == nil {
+		return "", ErrNotRecording
+	}
+	r.recorder.Stop()
+	name := filepath.Base(r.recorder.Path())
+	r.recorder = nil
+	log.Printf("Stopped recording room %s (%s)", r.ID, name)
+	return name, nil
+}
+
+// Recording returns the file the room is being recorded into, or ""
+func (r *Room) Recording() string {
+	r.recMu.Lock()
+	defer r.recMu.Unlock()
+	if r.recorder == nil {
+		return ""
+	}
+	return filepath.Base(r.recorder.Path())
+}
+
+// Replays lists the replay files, oldest first
+func (m *RoomManager) Replays() ([]replay.Info, error) {
+	if m.opts.ReplayDir == "" {
+		return nil, ErrReplaysDisabled
+	}
+	return replay.List(m.opts.ReplayDir)
+}
+
+// ReplayPath returns the path of the replay file called name
+func (m *RoomManager) ReplayPath(name string) (string, error) {
+	if m.opts.ReplayDir == "" {
+		return "", ErrReplaysDisabled
+	}
+	if !replay.ValidName(name) {
+		return "", ErrReplayNotFound
+	}
+	return filepath.Join(m.opts.ReplayDir, name), nil
+}
+
+// acquireReplay returns the room playing the replay file called name,
+// loading the file and starting playback if nobody is watching it yet. Every
+// viewer shares the room and its controls. Pair it with Release like Acquire.
+func (m *RoomManager) acquireReplay(name string) (*Room, error) {
+	path, err := m.ReplayPath(name)
+	if err != nil {
+		return nil, err
+	}
+	id := replayRoomPrefix + name
+
+	m.mu.Lock()
+	if room, ok := m.rooms[id]; ok {
+		room.refs++
+		m.mu.Unlock()
+		return room, nil
+	}
+	m.mu.Unlock()
+
+	// 파일을 읽는 동안은 잠그지 않음
+	rep, err := replay.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrReplayNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	pb, err := replay.NewPlayback(name, rep)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	room, ok := m.rooms[id]
+	if !ok {
+		// 재생은 지연 없이, 관심 영역 없이 방 전체를 보여 줌
+		opts := m.opts
+		opts.SimRate = rep.SimRate
+		opts.SpectatorDelay = 0
+		opts.Interest = Interest{}
+		room = m.newRoom(id, pb.Game(), pb, opts)
+		log.Printf("Room %s created (%d ticks, %v)", id, rep.EndTick()-rep.Start.Tick, rep.Duration().Round(time.Second))
+	}
+	room.refs++
+	return room, nil
+}
+
+// replayStatusMessage wraps a playback status in a message
+func replayStatusMessage(st replay.Status) models.Message {
+	return models.Message{Type: models.MessageTypeReplayStatus, Payload: st}
+}
+
+// sendReplayStatus tells every viewer where the playback is
+func (r *Room) sendReplayStatus() {
+	r.sendAll(replayStatusMessage(r.playback.Status()))
+}
+
+// controlReplay applies a viewer's replay_control: any of speed, paused and
+// seek (a tick number). Seeking re-simulates up to that tick, then everyone
+// gets a full game_state and the round as it is there.
+func (h *Handler) controlReplay(client *Client, req *models.ReplayControlRequest) {
+	room := client.room
+	pb := room.playback
+
+	// 일부만 적용되지 않도록 속도부터 확인
+	if req.Speed != nil && !slices.Contains(replay.Speeds, *req.Speed) {
+		client.reject(models.ErrorCodeInvalidPayload, invalidPayload(models.MessageTypeReplayControl,
+			&models.FieldError{Field: "speed", Reason: "must be 0.5, 1 or 4"}))
+		return
+	}
+	if req.Speed != nil {
+		pb.SetSpeed(*req.Speed)
+	}
+	if req.Paused != nil {
+		pb.SetPaused(*req.Paused)
+	}
+	if req.Seek != nil {
+		if err := pb.Seek(*req.Seek); err != nil {
+			log.Printf("Error seeking replay %s: %v", room.ID, err)
+			client.reject(models.ErrorCodeInternal, fmt.Errorf("seeking to tick %d: %w", *req.Seek, err))
+			return
+		}
+		room.mu.RLock()
+		for c := range room.clients {
+			c.requestFullState()
+		}
+		room.mu.RUnlock()
+		if st, ok := room.game.RoundState(); ok {
+			room.sendAll(roundMessage(st))
+		}
+	}
+	room.sendReplayStatus()
+}
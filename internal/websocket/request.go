@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/metrics"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 클라이언트 메시지를 거절하는 이유 (error 메시지의 message)
+var (
+	errNotLoggedIn = errors.New("log in first")
+	errLoggedIn    = errors.New("connection already has a player")
+	errDraining    = errors.New("server is draining and takes no new players")
+	errNotReplay   = errors.New("room is not playing a replay")
+	errBadToken    = errors.New("unknown or expired resume token")
+)
+
+// request is the decoded payload of a client message
+type request interface {
+	Validate() error
+}
+
+// requests maps every message type a client may send to its payload type.
+// Anything else (collision, welcome, ...) is refused as unknown_type.
+var requests = map[models.MessageType]func() request{
+	models.MessageTypeLogin:         func() request { return &models.PlayerLogin{} },
+	models.MessageTypeInput:         func() request { return &models.InputRequest{} },
+	models.MessageTypeJoinRoom:      func() request { return &models.JoinRoomRequest{} },
+	models.MessageTypeStateAck:      func() request { return &models.StateAck{} },
+	models.MessageTypeReconnect:     func() request { return &models.ReconnectRequest{} },
+	models.MessageTypeSpectate:      func() request { return &models.SpectateRequest{} },
+	models.MessageTypeChat:          func() request { return &models.ChatRequest{} },
+	models.MessageTypeReplayControl: func() request { return &models.ReplayControlRequest{} },
+}
+
+// decodeRequest reads a client frame into the payload struct registered for
+// its type and validates it. A frame that can't be used comes back with the
+// error code to refuse it with.
+func decodeRequest(codec Codec, data []byte) (Envelope, request, models.ErrorCode, error) {
+	env, err := codec.Decode(data)
+	if err != nil {
+		metrics.ReceivedInvalid()
+		return env, nil, models.ErrorCodeBadMessage, fmt.Errorf("can't decode %s message: %w", codec.Name(), err)
+	}
+	metrics.Received(env.Type)
+
+	newPayload, ok := requests[env.Type]
+	if !ok {
+		return env, nil, models.ErrorCodeUnknownType, fmt.Errorf("%q is not a message type clients can send", env.Type)
+	}
+
+	payload := newPayload()
+	if len(env.Payload) > 0 {
+		if err := codec.DecodePayload(env.Payload, payload); err != nil {
+			return env, nil, models.ErrorCodeInvalidPayload, invalidPayload(env.Type, payloadError(err))
+		}
+	}
+	if err := payload.Validate(); err != nil {
+		return env, nil, models.ErrorCodeInvalidPayload, invalidPayload(env.Type, err)
+	}
+	return env, payload, "", nil
+}
+
+// invalidPayload wraps a payload problem in the message sent back with
+// invalid_payload
+func invalidPayload(msgType models.MessageType, err error) error {
+	return fmt.Errorf("invalid %s: %w", msgType, err)
+}
+
+// payloadError turns a JSON type mismatch into a *models.FieldError that
+// says what the field should have been, rather than which Go type it is
+func payloadError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+	if typeErr.Field == "" {
+		return &models.FieldError{Reason: "payload must be " + jsonKind(typeErr.Type)}
+	}
+	return &models.FieldError{Field: typeErr.Field, Reason: "must be " + jsonKind(typeErr.Type)}
+}
+
+// jsonKind describes the JSON value a Go type is decoded from
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonKind(t.Elem())
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non-negative integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// reject tells the client the server refused the message it is handling,
+// naming the payload field when err wraps a *models.FieldError
+func (c *Client) reject(code models.ErrorCode, err error) {
+	e := models.ErrorPayload{
+		Code:    code,
+		Message: err.Error(),
+		Type:    c.handling.Type,
+		ID:      c.handling.ID,
+	}
+	var fieldErr *models.FieldError
+	if errors.As(err, &fieldErr) {
+		e.Field = fieldErr.Field
+	}
+	metrics.MessagesRejected.WithLabelValues(string(code)).Inc()
+	log.Printf("Rejected message from %s: %v (%s)", c.player.ID, err, code)
+	c.Send(models.Message{Type: models.MessageTypeError, Payload: e})
+}
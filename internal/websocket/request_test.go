@@ -0,0 +1,70 @@
+package ws
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	msgpack := func(msg models.Message) []byte {
+		data, err := MsgpackCodec.Encode(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name   string
+		codec  Codec
+		data   []byte
+		code   models.ErrorCode // ""면 받아들임
+		field  string
+		reason string  // 에러 메시지에 들어갈 말
+		want   request // 받아들였을 때의 payload
+	}{
+		{"not json", JSONCodec, []byte(`{"type":`), models.ErrorCodeBadMessage, "", "", nil},
+		{"server-only type", JSONCodec, []byte(`{"type":"welcome","payload":{}}`), models.ErrorCodeUnknownType, "", "", nil},
+		{"unknown type", JSONCodec, []byte(`{"type":"teleport"}`), models.ErrorCodeUnknownType, "", "", nil},
+		{"field of the wrong type", JSONCodec, []byte(`{"type":"input","payload":{"key":5}}`), models.ErrorCodeInvalidPayload, "key", "must be a string", nil},
+		{"negative seq", JSONCodec, []byte(`{"type":"state_ack","payload":{"seq":-1}}`), models.ErrorCodeInvalidPayload, "seq", "must be a non-negative integer", nil},
+		{"nested field of the wrong type", JSONCodec, []byte(`{"type":"login","payload":{"name":"a","lastPosition":{"x":"1"}}}`), models.ErrorCodeInvalidPayload, "lastPosition.x", "must be a number", nil},
+		{"payload not an object", JSONCodec, []byte(`{"type":"login","payload":[1]}`), models.ErrorCodeInvalidPayload, "", "payload must be an object", nil},
+		{"missing payload", JSONCodec, []byte(`{"type":"login"}`), models.ErrorCodeInvalidPayload, "name", "is required", nil},
+		{"fails validation", JSONCodec, []byte(`{"type":"chat","payload":{"channel":"dm","text":"hi"}}`), models.ErrorCodeInvalidPayload, "to", "is required for dm", nil},
+		{"valid", JSONCodec, []byte(`{"type":"input","id":"9","payload":{"seq":4,"key":"w"}}`), "", "", "", &models.InputRequest{Seq: 4, Key: "w"}},
+		{"valid without payload", JSONCodec, []byte(`{"type":"spectate"}`), "", "", "", &models.SpectateRequest{}},
+
+		{"msgpack garbage", MsgpackCodec, []byte{0xc1}, models.ErrorCodeBadMessage, "", "", nil},
+		{"msgpack fails validation", MsgpackCodec, msgpack(models.Message{Type: models.MessageTypeJoinRoom, Payload: models.JoinRoomRequest{}}), models.ErrorCodeInvalidPayload, "room", "is required", nil},
+		{"msgpack valid", MsgpackCodec, msgpack(models.Message{Type: models.MessageTypeStateAck, ID: "9", Payload: models.StateAck{Seq: 2}}), "", "", "", &models.StateAck{Seq: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, req, code, err := decodeRequest(tt.codec, tt.data)
+			if code != tt.code {
+				t.Fatalf("code %q (%v), want %q", code, err, tt.code)
+			}
+			if tt.code == "" {
+				if err != nil || !reflect.DeepEqual(req, tt.want) {
+					t.Errorf("got %#v, %v, want %#v", req, err, tt.want)
+				}
+				return
+			}
+			if err == nil || req != nil {
+				t.Fatalf("got %#v, %v, want an error", req, err)
+			}
+			var fieldErr *models.FieldError
+			if tt.code == models.ErrorCodeInvalidPayload && (!errors.As(err, &fieldErr) || fieldErr.Field != tt.field) {
+				t.Errorf("error %q names field %+v, want %q", err, fieldErr, tt.field)
+			}
+			if !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("error %q doesn't say %q", err, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,564 @@
+package ws
+
+import (
+	"log"
+	"regexp"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/chat"
+	"github.com/sangjinsu/websocket-multiplayer/internal/game"
+	"github.com/sangjinsu/websocket-multiplayer/internal/metrics"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+	"github.com/sangjinsu/websocket-multiplayer/internal/replay"
+)
+
+// DefaultRoomID is the room a connection joins when it doesn't ask for one
+const DefaultRoomID = "lobby"
+
+// 방 ID: 영문/숫자/-/_ 1~32자
+var roomIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// ValidRoomID reports whether id can be used as a room ID
+func ValidRoomID(id string) bool {
+	return roomIDPattern.MatchString(id)
+}
+
+// Room is an independent game world with its own tick loop
+type Room struct {
+	ID   string
+	game *game.Game
+
+	opts Options
+
+	refs    int             // 이 방에 머무는 연결 + 재접속 대기 중인 플레이어 수 (RoomManager.mu로 보호)
+	stop    chan struct{}   // tick 루프 종료 신호
+	done    chan struct{}   // tick 루프가 끝나면 닫힘
+	stopped sync.Once       // stop은 한 번만 닫음
+	history snapshotHistory // 최근 브로드캐스트한 스냅샷 (tick 루프 전용)
+	feed    spectatorFeed   // 지연 관전용 상태 (tick 루프 전용)
+	paused  atomic.Bool     // 관리자가 시뮬레이션을 멈춤
+
+	playback *replay.Playback // 리플레이를 재생하는 방이면 설정됨 (관전 전용)
+	recMu    sync.Mutex
+	recorder *replay.Recorder // 녹화 중이면 설정됨 (recMu로 보호)
+
+	mu      sync.RWMutex
+	clients map[*Client]struct{} // 이 방에 연결된 클라이언트 (로그인 전 포함)
+}
+
+// Game returns the room's game instance
+func (r *Room) Game() *game.Game {
+	return r.game
+}
+
+// 멈춤(GC, 부하) 뒤 한 번에 따라잡는 최대 step 수. 넘는 시간은 버려서
+// 따라잡느라 더 밀리는 악순환을 막는다.
+const maxCatchUpSteps = 5
+
+// run: 방마다 고정 timestep 게임 루프 실행.
+// 흐른 시간을 accumulator에 쌓아 SimRate 간격의 step을 필요한 만큼 실행하고,
+// 상태 전송은 SendRate 간격으로 따로 한다.
+func (r *Room) run() {
+	defer close(r.done)
+	step := time.Second / time.Duration(r.opts.SimRate)
+	sendInterval := time.Second / time.Duration(r.opts.SendRate)
+
+	ticker := time.NewTicker(step)
+	defer ticker.Stop()
+
+	last := time.Now()
+	var acc, sinceSend time.Duration
+	var collisions []models.CollisionEvent
+	var statusAt time.Time // 마지막 replay_status 전송
+	for {
+		select {
+		case <-r.stop:
+			return
+		case now := <-ticker.C:
+			acc += now.Sub(last)
+			last = now
+			if r.paused.Load() {
+				// 멈춘 동안에는 step 없이 전송만 (새로 들어온 연결도 상태를 받도록)
+				sinceSend += acc
+				acc = 0
+			}
+			if r.playback != nil {
+				// 리플레이는 재생 속도에 맞춰 Playback이 tick을 실행
+				collisions = append(collisions, r.playback.Advance(acc)...)
+				sinceSend += acc
+				acc = 0
+			}
+			if acc > maxCatchUpSteps*step {
+				acc = maxCatchUpSteps * step
+			}
+
+			steps := 0
+			for acc >= step {
+				collisions = append(collisions, r.game.Tick(step)...)
+				acc -= step
+				sinceSend += step
+				steps++
+			}
+			if steps > 0 && r.playback == nil {
+				r.updateBots(time.Duration(steps) * step)
+			}
+			for _, st := range r.game.TakeRoundChanges() {
+				r.broadcast(roundMessage(st), "")
+				r.watch(roundMessage(st))
+			}
+
+			if sinceSend >= sendInterval {
+				sinceSend %= sendInterval
+				r.broadcastGameState()
+				r.broadcastCollisions(collisions)
+				collisions = collisions[:0]
+				r.flushSpectators(now)
+			}
+			if r.playback != nil && now.Sub(statusAt) >= replayStatusInterval {
+				r.sendReplayStatus()
+				statusAt = now
+			}
+
+			// 한 번 도는 데 step보다 오래 걸리면 루프가 밀리기 시작함
+			if time.Since(now) > step {
+				metrics.TickOverruns.Inc()
+			}
+		}
+	}
+}
+
+// stopLoop stops the room's tick loop; it is safe to call more than once
+func (r *Room) stopLoop() {
+	r.stopped.Do(func() { close(r.stop) })
+}
+
+// RoomManager owns every room and tears down the empty ones
+type RoomManager struct {
+	mu       sync.Mutex
+	rooms    map[string]*Room
+	newGame  func() *game.Game
+	opts     Options
+	sessions *sessionStore
+	banned   map[string]struct{} // 접속을 거부할 IP (mu로 보호)
+	draining atomic.Bool         // 새 로그인 거부 (종료 준비)
+	closed   bool                // Shutdown이 연결을 닫기 시작함, 새 연결 거부 (mu로 보호)
+	closeAt  time.Time           // Shutdown이 연결을 닫을 시각, 종료 중이 아니면 0 (mu로 보호)
+	active   sync.WaitGroup      // 실행 중인 연결 핸들러
+}
+
+// NewRoomManager creates a room manager that builds each room's game with newGame
+func NewRoomManager(newGame func() *game.Game, opts Options) *RoomManager {
+	defaults := DefaultOptions()
+	if opts.SendQueueSize <= 0 {
+		opts.SendQueueSize = defaults.SendQueueSize
+	}
+	if opts.FullSnapshotInterval <= 0 {
+		opts.FullSnapshotInterval = defaults.FullSnapshotInterval
+	}
+	if opts.ReconnectGrace <= 0 {
+		opts.ReconnectGrace = defaults.ReconnectGrace
+	}
+	if opts.SimRate <= 0 {
+		opts.SimRate = defaults.SimRate
+	}
+	if opts.SendRate <= 0 || opts.SendRate > opts.SimRate {
+		opts.SendRate = opts.SimRate
+	}
+	if opts.Chat == nil {
+		opts.Chat = chat.New(chat.DefaultConfig())
+	}
+	return &RoomManager{
+		rooms:    make(map[string]*Room),
+		newGame:  newGame,
+		opts:     opts,
+		sessions: newSessionStore(),
+		banned:   make(map[string]struct{}),
+	}
+}
+
+// Acquire returns the room with the given ID, creating it and starting its
+// tick loop if needed. Every Acquire must be paired with a Release.
+func (m *RoomManager) Acquire(id string) *Room {
+	m.mu.Lock()
+	room, exists := m.rooms[id]
+	if !exists {
+		room = m.newRoom(id, m.newGame(), nil, m.opts)
+		log.Printf("Room %s created", id)
+	}
+	room.refs++
+	m.mu.Unlock()
+
+	// 방을 잡고 있으므로 녹화를 시작하는 동안 닫히지 않음
+	if !exists && m.opts.RecordReplays && m.opts.ReplayDir != "" {
+		if _, err := room.StartRecording(); err != nil {
+			log.Printf("Error recording room %s: %v", id, err)
+		}
+	}
+	return room
+}
+
+// newRoom registers a room running g, or playing pb back in g, and starts
+// its tick loop. The caller must hold m.mu and take the first reference.
+func (m *RoomManager) newRoom(id string, g *game.Game, pb *replay.Playback, opts Options) *Room {
+	room := &Room{
+		ID:       id,
+		game:     g,
+		opts:     opts,
+		playback: pb,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		history:  snapshotHistory{step: time.Second / time.Duration(opts.SimRate)},
+		feed:     spectatorFeed{history: snapshotHistory{step: time.Second / time.Duration(opts.SimRate)}},
+		clients:  make(map[*Client]struct{}),
+	}
+	m.rooms[id] = room
+	go room.run()
+	return room
+}
+
+// retain takes one more reference to a room that is already held
+func (m *RoomManager) retain(room *Room) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	room.refs++
+}
+
+// Release drops a connection's reference to the room and tears the room
+// down once nobody is left in it
+func (m *RoomManager) Release(room *Room) {
+	m.mu.Lock()
+	room.refs--
+	if room.refs > 0 {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.rooms, room.ID)
+	room.stopLoop()
+	m.opts.Chat.CloseRoom(room.ID)
+	m.mu.Unlock()
+
+	// 파일을 닫는 동안 다른 방을 막지 않도록 잠금 밖에서
+	room.StopRecording()
+	log.Printf("Room %s closed", room.ID)
+}
+
+// Get returns the room with the given ID, or nil if it doesn't exist
+func (m *RoomManager) Get(id string) *Room {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rooms[id]
+}
+
+// Rooms returns a snapshot of all live rooms
+func (m *RoomManager) Rooms() []*Room {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rooms := make([]*Room, 0, len(m.rooms))
+	for _, room := range m.rooms {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+// addClient registers a connection with the room
+func (r *Room) addClient(client *Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[client] = struct{}{}
+}
+
+// removeClient unregisters a connection from the room
+func (r *Room) removeClient(client *Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.clients, client)
+	client.spectator = false
+}
+
+// setLoggedIn marks whether the client's player is in the room's world; a
+// spectator that logs in stops spectating
+func (r *Room) setLoggedIn(client *Client, loggedIn bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	client.loggedIn = loggedIn
+	if loggedIn {
+		client.spectator = false
+	}
+}
+
+// logout clears the client's logged-in flag and reports whether it was set
+func (r *Room) logout(client *Client) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	was := client.loggedIn
+	client.loggedIn = false
+	return was
+}
+
+// isLoggedIn reports whether the client's player is in the room's world
+func (r *Room) isLoggedIn(client *Client) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return client.loggedIn
+}
+
+// isSpectator reports whether the client is watching the room without a player
+func (r *Room) isSpectator(client *Client) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return client.spectator
+}
+
+// attachPlayer binds an existing player of the room's world to the client
+func (r *Room) attachPlayer(client *Client, player *models.Player) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	client.player = player
+	client.loggedIn = true
+	client.spectator = false
+}
+
+// broadcast sends the message to every logged-in client except the one whose
+// player has excludeID
+func (r *Room) broadcast(message models.Message, excludeID string) {
+	frames := encodings{}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for client := range r.clients {
+		if client.loggedIn && client.player.ID != excludeID {
+			if data := frames.get(client.codec, message); data != nil {
+				client.enqueue(frame{data: data, typ: message.Type})
+			}
+		}
+	}
+}
+
+// playerPayload is the payload of player_join and player_enter
+func playerPayload(player models.PlayerState) models.PlayerJoin {
+	return models.PlayerJoin{
+		ID:        player.ID,
+		PlayerNum: player.PlayerNum,
+		Name:      player.Name,
+		X:         player.X,
+		Y:         player.Y,
+		Color:     player.Color,
+		Bot:       player.Bot,
+	}
+}
+
+// With interest management on, joins and leaves are sent by the tick loop
+// to the clients that can see the player (see sendInterestState)
+func (r *Room) broadcastPlayerJoin(player models.PlayerState) {
+	if r.opts.Interest.Enabled() {
+		return
+	}
+	msg := models.Message{
+		Type:    models.MessageTypePlayerJoin,
+		Payload: playerPayload(player),
+	}
+	r.broadcast(msg, player.ID)
+}
+
+// roundMessage builds the round_phase message for a round state
+func roundMessage(st models.RoundState) models.Message {
+	return models.Message{Type: models.MessageTypeRoundPhase, Payload: st}
+}
+
+// sendRound tells a client that just joined where the room's round is
+func (r *Room) sendRound(client *Client) {
+	if st, ok := r.game.RoundState(); ok {
+		client.Send(roundMessage(st))
+	}
+}
+
+func (r *Room) broadcastPlayerLeave(playerID string) {
+	if r.opts.Interest.Enabled() {
+		return
+	}
+	msg := models.Message{
+		Type:    models.MessageTypePlayerLeave,
+		Payload: models.PlayerLeave{ID: playerID},
+	}
+	r.broadcast(msg, "")
+}
+
+// updateBots runs the room's bots for dt of game time and announces the
+// ones that joined or left
+func (r *Room) updateBots(dt time.Duration) {
+	joined, left := r.game.Bots().Update(dt)
+	for _, id := range left {
+		r.broadcastPlayerLeave(id)
+	}
+	for _, state := range joined {
+		r.broadcastPlayerJoin(state)
+	}
+}
+
+// broadcastCollisions tells everyone in the room about the collisions of
+// the ticks since the last send, one collision message per tick
+func (r *Room) broadcastCollisions(events []models.CollisionEvent) {
+	for len(events) > 0 {
+		n := 1
+		for n < len(events) && events[n].Tick == events[0].Tick {
+			n++
+		}
+		// 지연 관전 피드가 메시지를 보관하고, 호출한 쪽은 events를 재사용하므로 복사
+		tick := slices.Clone(events[:n])
+		events = events[n:]
+
+		r.watch(collisionMessage(tick))
+		if r.opts.Interest.Enabled() {
+			r.broadcastInterestCollisions(tick)
+			continue
+		}
+		r.broadcast(collisionMessage(tick), "")
+	}
+}
+
+// collisionMessage wraps one tick's collision events in a message
+func collisionMessage(events []models.CollisionEvent) models.Message {
+	return models.Message{
+		Type: models.MessageTypeCollision,
+		Payload: models.CollisionPayload{
+			Tick:   events[0].Tick,
+			Events: events,
+		},
+	}
+}
+
+// broadcastPlayerMove tells everyone, the player included, that a player
+// jumped to a new position (e.g. teleported by an admin). With interest
+// management on, the next game_state carries it to those who can see it.
+func (r *Room) broadcastPlayerMove(player models.PlayerState) {
+	msg := models.Message{
+		Type:    models.MessageTypePlayerMove,
+		Payload: models.PlayerMove{ID: player.ID, X: player.X, Y: player.Y},
+	}
+	if r.opts.SpectatorDelay <= 0 {
+		// 지연 관전자는 지연된 game_state로 알게 됨 (feed는 tick 루프 전용)
+		r.sendSpectators(msg)
+	}
+	if r.opts.Interest.Enabled() {
+		return
+	}
+	r.broadcast(msg, "")
+}
+
+// 방 안의 플레이어에게 현재 상태 브로드캐스트 (변경사항이 있을 때만).
+// 각 클라이언트에는 마지막으로 ack한 스냅샷 대비 델타를, 필요하면 전체 스냅샷을 보낸다.
+func (r *Room) broadcastGameState() {
+	tick := r.game.TickCount()
+	players := r.game.GetAllPlayers()
+	states := make(map[string]models.PlayerState, len(players))
+	for id, p := range players {
+		states[id] = p.State()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// r.mu를 기다린 시간(입장/퇴장 등)은 빼고 전송만 잰다
+	now := time.Now()
+	defer func() { metrics.BroadcastDuration.Observe(time.Since(now).Seconds()) }()
+	delayed := r.opts.SpectatorDelay > 0
+	if delayed {
+		r.feed.queueState(tick, now, states)
+	}
+
+	// 이전 상태와 같고 전체 스냅샷을 기다리는 클라이언트도 없으면 보내지 않음
+	prev := r.history.latest()
+	if prev != nil && sameSnapshot(prev.players, states) && !r.anyNeedsFull(!delayed) {
+		return
+	}
+	snap := r.history.push(tick, now, states)
+
+	// 지연이 없으면 관전자도 같은 스냅샷을 받음 (관심 영역 없이 방 전체)
+	if !delayed {
+		r.sendSnapshots(&r.history, snap, isSpectator)
+	}
+
+	// 관심 영역을 쓰면 클라이언트마다 보이는 플레이어가 달라 공유할 인코딩이 없음
+	if r.opts.Interest.Enabled() {
+		for client := range r.clients {
+			if client.loggedIn {
+				r.sendInterestState(client, snap, prev)
+			}
+		}
+		return
+	}
+	r.sendSnapshots(&r.history, snap, isPlayer)
+}
+
+// sendSnapshots sends snap, or a delta against each client's acknowledged
+// snapshot in h, to the clients picked by want. The caller must hold r.mu.
+func (r *Room) sendSnapshots(h *snapshotHistory, snap *snapshot, want func(*Client) bool) {
+	// 같은 baseline/코덱을 가진 클라이언트끼리는 인코딩 결과를 공유
+	var full *models.Message
+	fullFrames := encodings{}
+	deltas := make(map[uint32]*encodedDelta)
+	interval := uint32(r.opts.FullSnapshotInterval)
+
+	for client := range r.clients {
+		if !want(client) {
+			continue
+		}
+
+		base := h.get(client.ackedSeq.Load())
+		if base == nil || client.needFull.Load() || snap.seq-client.lastFullSeq >= interval {
+			if full == nil {
+				msg := stateMessage(fullState(snap))
+				full = &msg
+			}
+			if client.needFull.Swap(false) {
+				client.minAck.Store(snap.seq)
+			}
+			client.lastFullSeq = snap.seq
+			if data := fullFrames.get(client.codec, *full); data != nil {
+				client.sendState(data)
+			}
+			continue
+		}
+
+		delta, cached := deltas[base.seq]
+		if !cached {
+			payload, changed := deltaState(base, snap)
+			delta = &encodedDelta{
+				message: stateMessage(payload),
+				changed: changed,
+				frames:  encodings{},
+			}
+			deltas[base.seq] = delta
+		}
+		if !delta.changed {
+			continue
+		}
+		if data := delta.frames.get(client.codec, delta.message); data != nil {
+			client.sendState(data)
+		}
+	}
+}
+
+// anyNeedsFull reports whether a logged-in client, or with spectators set
+// a spectator, is waiting for a full snapshot. The caller must hold r.mu.
+func (r *Room) anyNeedsFull(spectators bool) bool {
+	for client := range r.clients {
+		if (client.loggedIn || (spectators && client.spectator)) && client.needFull.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// isPlayer picks the clients whose player is in the world
+func isPlayer(client *Client) bool {
+	return client.loggedIn
+}
+
+// isSpectator picks the clients watching without a player
+func isSpectator(client *Client) bool {
+	return client.spectator
+}
@@ -0,0 +1,160 @@
+package ws
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 테스트용 방: tick 루프 없이 스냅샷을 직접 밀어 넣고 클라이언트 큐를 읽는다
+
+func newTestRoom(opts Options) *Room {
+	return &Room{
+		ID:      "test",
+		opts:    opts,
+		history: snapshotHistory{step: time.Second / 60},
+		clients: make(map[*Client]struct{}),
+	}
+}
+
+// newTestClient returns a logged-in client for playerID with no connection;
+// what the room sends it stays in its queue
+func newTestClient(r *Room, playerID string) *Client {
+	c := newClient(nil, JSONCodec, r.opts)
+	c.player = &models.Player{ID: playerID}
+	c.loggedIn = true
+	c.room = r
+	r.clients[c] = struct{}{}
+	return c
+}
+
+// pushTestSnapshot records the next snapshot of players in the room's history and
+// returns it with the snapshot before it
+func (r *Room) pushTestSnapshot(players ...models.PlayerState) (snap, prev *snapshot) {
+	states := make(map[string]models.PlayerState, len(players))
+	for _, p := range players {
+		states[p.ID] = p
+	}
+	prev = r.history.latest()
+	var tick uint64
+	if prev != nil {
+		tick = prev.tick + 1
+	}
+	return r.history.push(tick, time.Now(), states), prev
+}
+
+// received takes every message queued for c and decodes its envelope
+func received(t *testing.T, c *Client) []Envelope {
+	t.Helper()
+	c.mu.Lock()
+	queue := c.queue
+	c.queue = nil
+	c.mu.Unlock()
+
+	envs := make([]Envelope, 0, len(queue))
+	for _, f := range queue {
+		env, err := c.codec.Decode(f.data)
+		if err != nil {
+			t.Fatalf("decoding queued frame: %v", err)
+		}
+		envs = append(envs, env)
+	}
+	return envs
+}
+
+// decodePayload decodes env's payload as T
+func decodePayload[T any](t *testing.T, c *Client, env Envelope) T {
+	t.Helper()
+	var v T
+	if err := c.codec.DecodePayload(env.Payload, &v); err != nil {
+		t.Fatalf("decoding %s payload: %v", env.Type, err)
+	}
+	return v
+}
+
+func player(id string, x, y float64) models.PlayerState {
+	return models.PlayerState{ID: id, Name: id, X: x, Y: y}
+}
+
+// mirror rebuilds the room state from game_state messages the way a client
+// does, keeping every applied snapshot so deltas can name any of them
+type mirror struct {
+	states map[uint32]map[string]models.PlayerState
+	latest uint32
+}
+
+func newMirror() *mirror {
+	return &mirror{states: make(map[uint32]map[string]models.PlayerState)}
+}
+
+// apply applies one game_state payload and returns the resulting state
+func (m *mirror) apply(t *testing.T, s models.GameStatePayload) map[string]models.PlayerState {
+	t.Helper()
+	var next map[string]models.PlayerState
+	if s.Full {
+		next = maps.Clone(s.Players)
+		if next == nil {
+			next = make(map[string]models.PlayerState)
+		}
+	} else {
+		base, ok := m.states[s.Baseline]
+		if !ok {
+			t.Fatalf("delta %d against baseline %d the client never had", s.Seq, s.Baseline)
+		}
+		next = maps.Clone(base)
+		for id, d := range s.Changed {
+			old, ok := next[id]
+			if !ok {
+				old = models.PlayerState{ID: id}
+			}
+			next[id] = old.Apply(d)
+		}
+		for _, id := range s.Removed {
+			delete(next, id)
+		}
+	}
+	m.states[s.Seq] = next
+	m.latest = s.Seq
+	return next
+}
+
+// current is the most recently applied state
+func (m *mirror) current() map[string]models.PlayerState {
+	return m.states[m.latest]
+}
+
+func TestBroadcastCollisionsOneMessagePerTick(t *testing.T) {
+	r := newTestRoom(DefaultOptions())
+	c := newTestClient(r, "a")
+	events := []models.CollisionEvent{
+		{Tick: 5, A: "a", B: "b", Impulse: 100},
+		{Tick: 5, A: "c", B: "d", Impulse: 50},
+		{Tick: 7, A: "a", B: "c", Impulse: 20},
+	}
+	r.broadcastCollisions(events)
+
+	var got []models.CollisionPayload
+	for _, env := range received(t, c) {
+		if env.Type == models.MessageTypeCollision {
+			got = append(got, decodePayload[models.CollisionPayload](t, c, env))
+		}
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d collision messages, want one for tick 5 and one for tick 7", len(got))
+	}
+	for i, want := range []struct {
+		tick   uint64
+		events int
+	}{{5, 2}, {7, 1}} {
+		if got[i].Tick != want.tick || len(got[i].Events) != want.events {
+			t.Errorf("message %d: tick %d with %d events, want tick %d with %d", i, got[i].Tick, len(got[i].Events), want.tick, want.events)
+		}
+		for _, ev := range got[i].Events {
+			if ev.Tick != got[i].Tick {
+				t.Errorf("message for tick %d carries an event from tick %d", got[i].Tick, ev.Tick)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package ws
+
+import (
+	"crypto/rand"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// session ties a logged-in player to the connection currently driving it.
+// The player's resume token is the only way to take it over again after the
+// connection drops.
+type session struct {
+	token  string
+	room   *Room
+	player *models.Player
+	client *Client     // 현재 붙어 있는 연결, 끊겨서 유예 중이면 nil
+	ip     string      // 마지막으로 붙어 있던 연결의 주소 (차단용)
+	timer  *time.Timer // 유예 시간이 끝나면 플레이어를 제거 (끊긴 동안만)
+}
+
+// sessionStore maps resume tokens to players. A token is only ever sent in
+// its owner's welcome message and is replaced on every resume.
+type sessionStore struct {
+	mu       sync.Mutex
+	byToken  map[string]*session
+	byPlayer map[*models.Player]*session
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{
+		byToken:  make(map[string]*session),
+		byPlayer: make(map[*models.Player]*session),
+	}
+}
+
+// newResumeToken returns a random, unguessable token (128 bits)
+func newResumeToken() string {
+	return rand.Text()
+}
+
+// openSession registers the client's freshly joined player and returns its
+// resume token
+func (m *RoomManager) openSession(client *Client) string {
+	m.sessions.mu.Lock()
+	defer m.sessions.mu.Unlock()
+
+	s := &session{
+		token:  newResumeToken(),
+		room:   client.room,
+		player: client.player,
+		client: client,
+		ip:     client.ip,
+	}
+	m.sessions.byToken[s.token] = s
+	m.sessions.byPlayer[s.player] = s
+	return s.token
+}
+
+// closeSession forgets the client's player, e.g. when it leaves the world on
+// purpose. Its token stops working.
+func (m *RoomManager) closeSession(client *Client) {
+	m.sessions.mu.Lock()
+	defer m.sessions.mu.Unlock()
+
+	if s := m.sessions.byPlayer[client.player]; s != nil && s.client == client {
+		m.sessions.remove(s)
+	}
+}
+
+// detach is called when the client's connection drops while its player is in
+// the world. It reports whether the player stays: either it is kept for the
+// reconnect grace period, or another connection has already taken it over.
+// When it returns false the caller removes the player.
+func (m *RoomManager) detach(client *Client) bool {
+	m.sessions.mu.Lock()
+	defer m.sessions.mu.Unlock()
+
+	s := m.sessions.byPlayer[client.player]
+	if s == nil {
+		return false
+	}
+	if s.client != client {
+		// 이미 다른 연결이 토큰으로 이어받음
+		return true
+	}
+	if m.opts.NoReconnectGrace {
+		m.sessions.remove(s)
+		return false
+	}
+
+	// 유예 시간 동안 방이 닫히지 않도록 참조를 하나 잡아 둔다
+	m.retain(s.room)
+	s.client = nil
+	s.room.game.SetAway(s.player.ID, true)
+	s.timer = time.AfterFunc(m.opts.ReconnectGrace, func() { m.expire(s) })
+	return true
+}
+
+// resume hands the player behind token to client. The returned room carries
+// a reference for the client, which must be released like one from Acquire.
+// A still-connected owner is kicked off, so a client whose old socket hasn't
+// timed out yet can take its player back right away.
+func (m *RoomManager) resume(token string, client *Client) (room *Room, player *models.Player, newToken string, ok bool) {
+	m.sessions.mu.Lock()
+	defer m.sessions.mu.Unlock()
+
+	s := m.sessions.byToken[token]
+	if s == nil {
+		return nil, nil, "", false
+	}
+
+	if old := s.client; old != nil {
+		// 기존 연결이 살아 있으면 끊고 플레이어를 넘겨받음
+		s.room.setLoggedIn(old, false)
+		old.closeBecause("replaced")
+		m.retain(s.room)
+	} else {
+		// 유예 중이던 참조를 새 연결이 이어받음
+		s.timer.Stop()
+		s.timer = nil
+		s.room.game.SetAway(s.player.ID, false)
+	}
+
+	// 토큰은 한 번 쓰면 교체
+	delete(m.sessions.byToken, s.token)
+	s.token = newResumeToken()
+	m.sessions.byToken[s.token] = s
+	s.client = client
+	s.ip = client.ip
+	return s.room, s.player, s.token, true
+}
+
+// expire removes a player whose grace period ran out without a reconnect
+func (m *RoomManager) expire(s *session) {
+	m.sessions.mu.Lock()
+	if s.client != nil || m.sessions.byToken[s.token] != s {
+		// 그 사이에 재접속함
+		m.sessions.mu.Unlock()
+		return
+	}
+	m.sessions.remove(s)
+	m.sessions.mu.Unlock()
+
+	m.opts.Chat.Forget(s.player.ID)
+	s.room.game.RemovePlayer(s.player.ID)
+	s.room.broadcastPlayerLeave(s.player.ID)
+	log.Printf("Player %s (%s) did not reconnect in time, removed from room %s", s.player.Name, s.player.ID, s.room.ID)
+	m.Release(s.room)
+}
+
+func (st *sessionStore) remove(s *session) {
+	delete(st.byToken, s.token)
+	delete(st.byPlayer, s.player)
+}
@@ -0,0 +1,161 @@
+package ws
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// welcome returns the welcome payload queued for client
+func welcome(t *testing.T, client *Client) models.WelcomePayload {
+	t.Helper()
+	for _, env := range received(t, client) {
+		if env.Type == models.MessageTypeWelcome {
+			return decodePayload[models.WelcomePayload](t, client, env)
+		}
+	}
+	t.Fatal("no welcome")
+	return models.WelcomePayload{}
+}
+
+// dropped logs a player in on a new connection in roomID, drops the
+// connection and returns the player with its resume token
+func (h *Handler) dropped(t *testing.T, roomID string) (*models.Player, string) {
+	t.Helper()
+	client := h.connect(roomID)
+	h.login(t, client, "ada")
+	token := welcome(t, client).ResumeToken
+	h.leaveRoom(client)
+	return client.player, token
+}
+
+func TestResumeReplacesToken(t *testing.T) {
+	h := newTestHandler(DefaultOptions())
+	player, token := h.dropped(t, "a")
+
+	client := h.connect("a")
+	defer h.leaveRoom(client)
+	h.handleMessage(client, &models.ReconnectRequest{Token: token})
+	got := welcome(t, client)
+	if !got.Resumed || got.ID != player.ID || client.player != player {
+		t.Fatalf("welcome %+v, want player %s resumed", got, player.ID)
+	}
+	if got.ResumeToken == "" || got.ResumeToken == token {
+		t.Errorf("resume token %q, want a new one", got.ResumeToken)
+	}
+	if st, ok := client.room.game.PlayerState(player.ID); !ok || st.Away {
+		t.Errorf("player state %+v, %v, want back from away", st, ok)
+	}
+}
+
+func TestResumeRejectsBadToken(t *testing.T) {
+	h := newTestHandler(DefaultOptions())
+	_, token := h.dropped(t, "a")
+	owner := h.connect("a")
+	defer h.leaveRoom(owner)
+	h.handleMessage(owner, &models.ReconnectRequest{Token: token})
+
+	for name, token := range map[string]string{"reused": token, "unknown": "AAAAAAAAAAAAAAAAAAAAAAAAAA"} {
+		t.Run(name, func(t *testing.T) {
+			client := h.connect("a")
+			defer h.leaveRoom(client)
+			h.handleMessage(client, &models.ReconnectRequest{Token: token})
+			if codes := errorCodes(t, client); !slices.Equal(codes, []models.ErrorCode{models.ErrorCodeInvalidToken}) {
+				t.Errorf("got errors %v, want [invalid_token]", codes)
+			}
+			if client.room.isLoggedIn(client) || client.player == owner.player {
+				t.Error("a bad token took over the player")
+			}
+		})
+	}
+}
+
+func TestLoginWithResumeToken(t *testing.T) {
+	h := newTestHandler(DefaultOptions())
+	player, token := h.dropped(t, "a")
+
+	// 토큰이 맞으면 이름 없이도 이어받음
+	client := h.connect("a")
+	defer h.leaveRoom(client)
+	h.handleMessage(client, &models.PlayerLogin{ResumeToken: token})
+	if got := welcome(t, client); !got.Resumed || got.ID != player.ID || got.Name != "ada" {
+		t.Errorf("welcome %+v, want ada resumed", got)
+	}
+
+	// 토큰이 틀리면 새로 로그인하는데, 그러려면 이름이 있어야 함
+	nameless := h.connect("a")
+	defer h.leaveRoom(nameless)
+	h.handleMessage(nameless, &models.PlayerLogin{ResumeToken: token})
+	if codes := errorCodes(t, nameless); !slices.Equal(codes, []models.ErrorCode{models.ErrorCodeInvalidToken}) {
+		t.Errorf("got errors %v, want [invalid_token]", codes)
+	}
+	named := h.connect("a")
+	defer h.leaveRoom(named)
+	h.handleMessage(named, &models.PlayerLogin{Name: "bob", ResumeToken: token})
+	if got := welcome(t, named); got.Resumed || got.Name != "bob" {
+		t.Errorf("welcome %+v, want a fresh login as bob", got)
+	}
+}
+
+func TestResumeAfterGraceExpires(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ReconnectGrace = 50 * time.Millisecond
+	h := newTestHandler(opts)
+	player, token := h.dropped(t, "a")
+
+	// 유예 중에 잡아 두었던 참조를 놓으므로 방도 닫힘
+	deadline := time.Now().Add(2 * time.Second)
+	for h.rooms.Get("a") != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("room still open after the grace period")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	client := h.connect("a")
+	defer h.leaveRoom(client)
+	h.handleMessage(client, &models.ReconnectRequest{Token: token})
+	if codes := errorCodes(t, client); !slices.Equal(codes, []models.ErrorCode{models.ErrorCodeInvalidToken}) {
+		t.Errorf("got errors %v, want [invalid_token]", codes)
+	}
+	if _, ok := client.room.game.PlayerState(player.ID); ok {
+		t.Error("player still in the world after the grace period")
+	}
+}
+
+func TestGraceKeepsRoomOpen(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ReconnectGrace = time.Minute
+	h := newTestHandler(opts)
+	client := h.connect("a")
+	h.login(t, client, "ada")
+	token := welcome(t, client).ResumeToken
+	room, player := client.room, client.player
+	h.leaveRoom(client)
+
+	if h.rooms.Get("a") != room {
+		t.Fatal("room closed while its player waits for a reconnect")
+	}
+	if st, ok := room.game.PlayerState(player.ID); !ok || !st.Away {
+		t.Errorf("player state %+v, %v, want away", st, ok)
+	}
+
+	// 다른 방으로 접속해도 플레이어가 있는 방으로 돌아감
+	back := h.connect("b")
+	h.handleMessage(back, &models.ReconnectRequest{Token: token})
+	if back.room != room {
+		t.Fatalf("resumed in room %s, want %s", back.room.ID, room.ID)
+	}
+	if h.rooms.Get("b") != nil {
+		t.Error("room b still open after its only connection left")
+	}
+
+	// 유예 참조를 새 연결이 넘겨받았으므로 나가면 방이 닫힘
+	h.leaveWorld(back, false)
+	h.leaveRoom(back)
+	if h.rooms.Get("a") != nil {
+		t.Error("room still open after the resumed player left")
+	}
+}
@@ -0,0 +1,210 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofiber/websocket/v2"
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// SetDraining turns drain mode on or off. While draining, new logins are
+// refused; players already in a world keep playing and can still resume.
+func (m *RoomManager) SetDraining(draining bool) {
+	m.draining.Store(draining)
+	if draining {
+		log.Printf("Drain mode on, refusing new logins")
+	} else {
+		log.Printf("Drain mode off")
+	}
+}
+
+// Draining reports whether new logins are refused
+func (m *RoomManager) Draining() bool {
+	return m.draining.Load()
+}
+
+// enter registers a connection handler, or reports false once the manager
+// has shut down and no new connection may start
+func (m *RoomManager) enter() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return false
+	}
+	m.active.Add(1)
+	return true
+}
+
+// shutdownMessage is the server_shutdown warning for connections closing at
+// closeAt
+func shutdownMessage(closeAt time.Time) models.Message {
+	return models.Message{
+		Type: models.MessageTypeServerShutdown,
+		Payload: models.ServerShutdown{
+			Seconds: max(0, time.Until(closeAt).Seconds()),
+			At:      closeAt.UnixMilli(),
+		},
+	}
+}
+
+// warnShutdown sends client the server_shutdown warning, with the time left,
+// if Shutdown is counting down. Connections that resume or start spectating
+// after Shutdown warned everyone get it this way.
+func (m *RoomManager) warnShutdown(client *Client) {
+	m.mu.Lock()
+	closeAt := m.closeAt
+	m.mu.Unlock()
+	if !closeAt.IsZero() {
+		client.Send(shutdownMessage(closeAt))
+	}
+}
+
+// exit marks a connection handler registered with enter as finished
+func (m *RoomManager) exit() {
+	m.active.Done()
+}
+
+// Shutdown stops every room gracefully:
+//
+//  1. drain mode, so nobody new logs in
+//  2. server_shutdown to every connection, countdown ahead of time, and to
+//     every connection that resumes or starts spectating during it
+//  3. after the countdown, the tick loops stop
+//  4. recordings are closed and the final state is written to snapshotPath
+//     (skipped if empty)
+//  5. every socket is closed with 1001 (going away)
+//
+// It returns once all connection handlers have finished, or early when ctx
+// is done. The error is from saving the snapshot or ctx.
+func (m *RoomManager) Shutdown(ctx context.Context, countdown time.Duration, snapshotPath string) error {
+	m.SetDraining(true)
+	m.mu.Lock()
+	m.closeAt = time.Now().Add(countdown)
+	msg := shutdownMessage(m.closeAt)
+	m.mu.Unlock()
+	for _, room := range m.Rooms() {
+		room.sendAll(msg)
+	}
+	log.Printf("Shutting down in %v", countdown)
+
+	select {
+	case <-time.After(countdown):
+	case <-ctx.Done():
+	}
+
+	// 새 연결을 막은 뒤 방의 tick 루프를 멈춤
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+	rooms := m.Rooms()
+	for _, room := range rooms {
+		room.stopLoop()
+	}
+	for _, room := range rooms {
+		select {
+		case <-room.done:
+		case <-ctx.Done():
+		}
+	}
+	// 녹화 중인 리플레이는 끝까지 써서 닫음
+	for _, room := range rooms {
+		room.StopRecording()
+	}
+
+	var err error
+	if snapshotPath != "" {
+		if err = m.SaveSnapshot(snapshotPath); err != nil {
+			log.Printf("Error saving snapshot: %v", err)
+		} else {
+			log.Printf("Saved snapshot of %d room(s) to %s", len(rooms), snapshotPath)
+		}
+	}
+
+	for _, room := range rooms {
+		room.mu.RLock()
+		for client := range room.clients {
+			client.CloseWith(websocket.CloseGoingAway, "server shutting down")
+		}
+		room.mu.RUnlock()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.active.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+	}
+	return err
+}
+
+// RoomSnapshot is one room's whole state: what the admin API dumps and what
+// a shutdown saves
+type RoomSnapshot struct {
+	ID     string             `json:"room"`
+	Tick   uint64             `json:"tick"`
+	Paused bool               `json:"paused"`
+	World  models.WorldConfig `json:"world"`
+	Round  *models.RoundState `json:"round,omitempty"`
+	State  *models.GameState  `json:"state"`
+}
+
+// Snapshot returns a copy of the room's state
+func (r *Room) Snapshot() RoomSnapshot {
+	snap := RoomSnapshot{
+		ID:     r.ID,
+		Tick:   r.game.TickCount(),
+		Paused: r.Paused(),
+		World:  r.game.Config(),
+		State:  r.game.Dump(),
+	}
+	if st, ok := r.game.RoundState(); ok {
+		snap.Round = &st
+	}
+	return snap
+}
+
+// SaveSnapshot writes every room's state to path as JSON. The file is
+// replaced in one step, so a crash mid-write leaves the old one intact.
+func (m *RoomManager) SaveSnapshot(path string) error {
+	rooms := m.Rooms()
+	snap := struct {
+		SavedAt time.Time      `json:"savedAt"`
+		Rooms   []RoomSnapshot `json:"rooms"`
+	}{SavedAt: time.Now(), Rooms: make([]RoomSnapshot, 0, len(rooms))}
+	for _, room := range rooms {
+		snap.Rooms = append(snap.Rooms, room.Snapshot())
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package ws
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// shutdownWarnings returns the server_shutdown payloads queued for client
+func shutdownWarnings(t *testing.T, client *Client) []models.ServerShutdown {
+	t.Helper()
+	var out []models.ServerShutdown
+	for _, env := range received(t, client) {
+		if env.Type == models.MessageTypeServerShutdown {
+			out = append(out, decodePayload[models.ServerShutdown](t, client, env))
+		}
+	}
+	return out
+}
+
+func TestShutdownCountdown(t *testing.T) {
+	const countdown = 400 * time.Millisecond
+	h := newTestHandler(DefaultOptions())
+	_, token := h.dropped(t, "a")
+	early := h.connect("a")
+	h.login(t, early, "bob")
+	received(t, early)
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- h.rooms.Shutdown(context.Background(), countdown, "") }()
+
+	// 이미 연결된 클라이언트는 한 번, 카운트다운 전체를 받음
+	var warned []models.ServerShutdown
+	for len(warned) == 0 && time.Since(start) < countdown/4 {
+		time.Sleep(time.Millisecond)
+		warned = append(warned, shutdownWarnings(t, early)...)
+	}
+	if len(warned) != 1 || warned[0].Seconds > countdown.Seconds() || warned[0].Seconds < countdown.Seconds()-0.1 {
+		t.Fatalf("early connection got %+v, want one warning of about %v", warned, countdown)
+	}
+	closeAt := warned[0].At
+	if want := start.Add(countdown).UnixMilli(); closeAt < want-50 || closeAt > want+50 {
+		t.Errorf("closing at %d, want about %d", closeAt, want)
+	}
+
+	// 카운트다운 중에 돌아오거나 관전을 시작한 연결은 남은 시간을 받음
+	time.Sleep(countdown / 2)
+	resumed := h.connect("a")
+	h.handleMessage(resumed, &models.ReconnectRequest{Token: token})
+	spectator := h.connect("a")
+	h.handleMessage(spectator, &models.SpectateRequest{})
+	for name, client := range map[string]*Client{"resumed": resumed, "spectator": spectator} {
+		warned := shutdownWarnings(t, client)
+		if len(warned) != 1 || warned[0].At != closeAt || warned[0].Seconds >= countdown.Seconds()/2+0.01 || warned[0].Seconds <= 0 {
+			t.Errorf("%s connection got %+v, want one warning for %d with under %v left", name, warned, closeAt, countdown/2)
+		}
+	}
+
+	// 새 로그인은 거절
+	late := h.connect("a")
+	h.handleMessage(late, &models.PlayerLogin{Name: "eve"})
+	if codes := errorCodes(t, late); !slices.Equal(codes, []models.ErrorCode{models.ErrorCodeDraining}) {
+		t.Errorf("login during the countdown got errors %v, want [draining]", codes)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown did not return")
+	}
+	if elapsed := time.Since(start); elapsed < countdown {
+		t.Errorf("Shutdown returned after %v, before the %v countdown", elapsed, countdown)
+	}
+	if !closing(early) || !closing(spectator) {
+		t.Error("connections not closed after the countdown")
+	}
+}
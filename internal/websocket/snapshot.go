@@ -0,0 +1,129 @@
+package ws
+
+import (
+	"maps"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// 방이 보관하는 최근 스냅샷 수 (60Hz 전송 기준 약 2초).
+// 클라이언트의 ack가 이보다 오래되면 전체 스냅샷을 다시 보낸다.
+const snapshotHistorySize = 128
+
+// snapshot is the room state at one broadcast
+type snapshot struct {
+	seq     uint32
+	tick    uint64
+	step    time.Duration // 이 방의 물리 step 간격
+	at      time.Time
+	players map[string]models.PlayerState
+}
+
+// snapshotHistory keeps the last few snapshots so deltas can be computed
+// against whichever one a client acknowledged. Only the tick loop uses it.
+type snapshotHistory struct {
+	seq  uint32
+	step time.Duration
+	ring [snapshotHistorySize]*snapshot
+}
+
+// push records the next snapshot and returns it
+func (h *snapshotHistory) push(tick uint64, at time.Time, players map[string]models.PlayerState) *snapshot {
+	h.seq++
+	if h.seq == 0 {
+		h.seq = 1 // 0은 "ack 없음"을 뜻하므로 건너뜀
+	}
+	snap := &snapshot{seq: h.seq, tick: tick, step: h.step, at: at, players: players}
+	h.ring[h.seq%snapshotHistorySize] = snap
+	return snap
+}
+
+// latest returns the most recent snapshot, or nil before the first one
+func (h *snapshotHistory) latest() *snapshot {
+	return h.get(h.seq)
+}
+
+// get returns the snapshot with the given seq if it is still kept
+func (h *snapshotHistory) get(seq uint32) *snapshot {
+	if seq == 0 {
+		return nil
+	}
+	snap := h.ring[seq%snapshotHistorySize]
+	if snap == nil || snap.seq != seq {
+		return nil
+	}
+	return snap
+}
+
+// envelope returns the game_state payload header shared by full snapshots
+// and deltas
+func (snap *snapshot) envelope() models.GameStatePayload {
+	return models.GameStatePayload{
+		Seq:          snap.seq,
+		Tick:         snap.tick,
+		ServerTime:   snap.at.UnixMilli(),
+		TickInterval: float64(snap.step) / float64(time.Millisecond),
+	}
+}
+
+// fullState builds a full game_state payload for snap
+func fullState(snap *snapshot) models.GameStatePayload {
+	payload := snap.envelope()
+	payload.Full = true
+	payload.Players = snap.players
+	return payload
+}
+
+// deltaState builds a game_state payload holding only what changed between
+// base and snap. ok is false when nothing changed.
+func deltaState(base, snap *snapshot) (payload models.GameStatePayload, ok bool) {
+	payload = snap.envelope()
+	payload.Baseline = base.seq
+	ok = diffPlayers(&payload, base.players, snap.players)
+	return payload, ok
+}
+
+// diffPlayers fills the payload's Changed and Removed with the difference
+// between two player maps and reports whether there was any
+func diffPlayers(payload *models.GameStatePayload, from, to map[string]models.PlayerState) bool {
+	for id, cur := range to {
+		old, existed := from[id]
+		if !existed {
+			old = models.PlayerState{ID: id}
+		}
+		if d, changed := cur.Diff(old); changed || !existed {
+			if payload.Changed == nil {
+				payload.Changed = make(map[string]models.PlayerDelta)
+			}
+			payload.Changed[id] = d
+		}
+	}
+	for id := range from {
+		if _, exists := to[id]; !exists {
+			payload.Removed = append(payload.Removed, id)
+		}
+	}
+	return len(payload.Changed) > 0 || len(payload.Removed) > 0
+}
+
+// stateMessage wraps a game_state payload in a message
+func stateMessage(payload models.GameStatePayload) models.Message {
+	return models.Message{
+		Type:    models.MessageTypeGameState,
+		Payload: payload,
+	}
+}
+
+// encodedDelta is one delta message shared by every client with the same
+// baseline, encoded lazily per codec
+type encodedDelta struct {
+	message models.Message
+	changed bool
+	frames  encodings
+}
+
+// sameSnapshot reports whether two player maps hold identical states
+func sameSnapshot(a, b map[string]models.PlayerState) bool {
+	return maps.Equal(a, b)
+}
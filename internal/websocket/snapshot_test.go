@@ -0,0 +1,264 @@
+package ws
+
+import (
+	"fmt"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// broadcastTestSnapshot pushes a snapshot of players and sends it to every
+// logged-in client the way broadcastGameState does
+func (r *Room) broadcastTestSnapshot(players ...models.PlayerState) *snapshot {
+	snap, _ := r.pushTestSnapshot(players...)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	r.sendSnapshots(&r.history, snap, isPlayer)
+	return snap
+}
+
+// states returns the game_state payloads queued for c
+func states(t *testing.T, c *Client) []models.GameStatePayload {
+	t.Helper()
+	var out []models.GameStatePayload
+	for _, env := range received(t, c) {
+		if env.Type == models.MessageTypeGameState {
+			out = append(out, decodePayload[models.GameStatePayload](t, c, env))
+		}
+	}
+	return out
+}
+
+func TestDeltaChainRebuildsState(t *testing.T) {
+	joined := time.UnixMilli(1700000000000).UTC()
+	a := models.PlayerState{ID: "a", PlayerNum: 1, Name: "alice", X: 100, Y: 100, Color: "#FF0000", JoinedAt: joined}
+	b := models.PlayerState{ID: "b", PlayerNum: 2, Name: "bob", X: 300, Y: 200, Color: "#00FF00", JoinedAt: joined}
+	moved := func(p models.PlayerState, dx, dy float64) models.PlayerState {
+		p.X += dx
+		p.Y += dy
+		p.Vx, p.Vy = dx*60, dy*60
+		return p
+	}
+	with := func(p models.PlayerState, f func(*models.PlayerState)) models.PlayerState {
+		f(&p)
+		return p
+	}
+
+	frames := [][]models.PlayerState{
+		{a},
+		{moved(a, 1, 0)},
+		{moved(a, 2, 0), b},               // b 입장
+		{moved(a, 2, 0), b},               // 변화 없음
+		{moved(a, 3, 1), moved(b, -1, 0)}, // 둘 다 이동
+		{moved(a, 3, 1)},                  // b 퇴장
+		{with(moved(a, 3, 1), func(p *models.PlayerState) { p.It, p.Score, p.LastInputSeq = true, 2, 17 })},
+		{with(moved(a, 3, 1), func(p *models.PlayerState) { p.It, p.Away = false, true }), b}, // b 재입장
+		{}, // 모두 퇴장
+		{b},
+	}
+
+	acks := []struct {
+		name string
+		ack  func(n int) bool // n번째로 받은 상태를 ack할지
+	}{
+		{"ack every state", func(int) bool { return true }},
+		{"ack only the first", func(n int) bool { return n == 0 }},
+		{"ack every third", func(n int) bool { return n%3 == 0 }},
+		{"never ack", func(int) bool { return false }},
+	}
+
+	for _, tt := range acks {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRoom(DefaultOptions())
+			c := newTestClient(r, "a")
+			c.requestFullState()
+			m := newMirror()
+
+			received := 0
+			for i, players := range frames {
+				snap := r.broadcastTestSnapshot(players...)
+				for _, st := range states(t, c) {
+					m.apply(t, st)
+					if tt.ack(received) {
+						c.ack(st.Seq)
+					}
+					received++
+				}
+				if got := m.current(); !maps.Equal(got, snap.players) {
+					t.Fatalf("frame %d: client has %v, want %v", i, got, snap.players)
+				}
+			}
+		})
+	}
+}
+
+func TestDeltaOfNewPlayerLeavesOutZeroFields(t *testing.T) {
+	b := models.PlayerState{ID: "b", PlayerNum: 2, Name: "bob", X: 300, Y: 200, Vx: -60, Color: "#00FF00", Score: 3}
+	var payload models.GameStatePayload
+	diffPlayers(&payload, map[string]models.PlayerState{}, map[string]models.PlayerState{"b": b})
+
+	d, ok := payload.Changed["b"]
+	if !ok {
+		t.Fatalf("changed %v, want b", payload.Changed)
+	}
+	if d.Name == nil || d.X == nil || d.Vx == nil || d.Score == nil {
+		t.Errorf("delta %+v is missing a field b set", d)
+	}
+	if d.Vy != nil || d.Away != nil || d.It != nil || d.Bot != nil || d.LastInputSeq != nil || d.JoinedAt != nil {
+		t.Errorf("delta %+v carries a zero field", d)
+	}
+	if got := (models.PlayerState{ID: "b"}).Apply(d); got != b {
+		t.Errorf("applied to an empty state %+v, want %+v", got, b)
+	}
+}
+
+func TestDeltaBaselineFollowsAcks(t *testing.T) {
+	p := func(x float64) models.PlayerState { return player("a", x, 0) }
+
+	tests := []struct {
+		name string
+		// run drives the room and returns the state the client gets next
+		run          func(t *testing.T, r *Room, c *Client) models.GameStatePayload
+		wantFull     bool
+		wantBaseline uint32
+	}{
+		{
+			name: "delta against the latest ack",
+			run: func(t *testing.T, r *Room, c *Client) models.GameStatePayload {
+				r.broadcastTestSnapshot(p(1))
+				c.ack(1)
+				r.broadcastTestSnapshot(p(2))
+				c.ack(2)
+				return next(t, r, c, p(3))
+			},
+			wantBaseline: 2,
+		},
+		{
+			name: "stale ack is ignored",
+			run: func(t *testing.T, r *Room, c *Client) models.GameStatePayload {
+				for x := 1.0; x <= 3; x++ {
+					r.broadcastTestSnapshot(p(x))
+				}
+				c.ack(3)
+				c.ack(2) // 순서가 바뀌어 늦게 도착한 ack
+				return next(t, r, c, p(4))
+			},
+			wantBaseline: 3,
+		},
+		{
+			name: "ack too old for the ring",
+			run: func(t *testing.T, r *Room, c *Client) models.GameStatePayload {
+				r.broadcastTestSnapshot(p(1))
+				c.ack(1)
+				for i := 0; i < snapshotHistorySize; i++ {
+					r.broadcastTestSnapshot(p(float64(i + 2)))
+				}
+				received(t, c)
+				return next(t, r, c, p(-1))
+			},
+			wantFull: true,
+		},
+		{
+			name: "ack sent before a forced full snapshot",
+			run: func(t *testing.T, r *Room, c *Client) models.GameStatePayload {
+				r.broadcastTestSnapshot(p(1))
+				r.broadcastTestSnapshot(p(2))
+				c.requestFullState() // 방 이동/재접속
+				c.ack(2)             // 그 전에 보낸 ack가 이제 도착
+				return next(t, r, c, p(3))
+			},
+			wantFull: true,
+		},
+		{
+			name: "ack of a snapshot before the forced full one",
+			run: func(t *testing.T, r *Room, c *Client) models.GameStatePayload {
+				r.broadcastTestSnapshot(p(1))
+				c.requestFullState()
+				r.broadcastTestSnapshot(p(2)) // 전체 스냅샷, minAck = 2
+				c.ack(1)
+				return next(t, r, c, p(3))
+			},
+			wantFull: true,
+		},
+		{
+			name: "ack of the forced full snapshot",
+			run: func(t *testing.T, r *Room, c *Client) models.GameStatePayload {
+				r.broadcastTestSnapshot(p(1))
+				c.requestFullState()
+				r.broadcastTestSnapshot(p(2))
+				c.ack(2)
+				return next(t, r, c, p(3))
+			},
+			wantBaseline: 2,
+		},
+		{
+			name: "full snapshot every FullSnapshotInterval",
+			run: func(t *testing.T, r *Room, c *Client) models.GameStatePayload {
+				for i := 1; i <= r.opts.FullSnapshotInterval; i++ { // 1번이 첫 전체 스냅샷
+					r.broadcastTestSnapshot(p(float64(i)))
+					c.ack(uint32(i))
+				}
+				return next(t, r, c, p(-1))
+			},
+			wantFull: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.FullSnapshotInterval = 20
+			r := newTestRoom(opts)
+			c := newTestClient(r, "a")
+			c.requestFullState()
+
+			got := tt.run(t, r, c)
+			if got.Full != tt.wantFull || got.Baseline != tt.wantBaseline {
+				t.Errorf("got full=%v baseline=%d, want full=%v baseline=%d", got.Full, got.Baseline, tt.wantFull, tt.wantBaseline)
+			}
+		})
+	}
+}
+
+// next drops what c has received so far, broadcasts one more snapshot and
+// returns the state c gets for it
+func next(t *testing.T, r *Room, c *Client, players ...models.PlayerState) models.GameStatePayload {
+	t.Helper()
+	received(t, c)
+	r.broadcastTestSnapshot(players...)
+	got := states(t, c)
+	if len(got) != 1 {
+		t.Fatalf("got %d game_state messages, want 1", len(got))
+	}
+	return got[0]
+}
+
+func TestDeltasAreSharedPerBaseline(t *testing.T) {
+	r := newTestRoom(DefaultOptions())
+	clients := make([]*Client, 4)
+	for i := range clients {
+		clients[i] = newTestClient(r, fmt.Sprintf("p%d", i))
+	}
+	r.broadcastTestSnapshot(player("a", 0, 0))
+	r.broadcastTestSnapshot(player("a", 1, 0))
+	clients[0].ack(1)
+	clients[1].ack(1)
+	clients[2].ack(2)
+	for _, c := range clients {
+		received(t, c)
+	}
+
+	r.broadcastTestSnapshot(player("a", 2, 0))
+	want := []struct {
+		full     bool
+		baseline uint32
+	}{{false, 1}, {false, 1}, {false, 2}, {true, 0}}
+	for i, c := range clients {
+		got := states(t, c)
+		if len(got) != 1 || got[0].Full != want[i].full || got[0].Baseline != want[i].baseline {
+			t.Errorf("client %d got %+v, want full=%v baseline=%d", i, got, want[i].full, want[i].baseline)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ws
+
+import (
+	"log"
+	"time"
+
+	"github.com/sangjinsu/websocket-multiplayer/internal/models"
+)
+
+// spectatorFeed holds a room's broadcasts back for Options.SpectatorDelay.
+// Every state send is queued together with the messages that followed it
+// (collisions, round phases), and replayed to spectators once it is old
+// enough, with its own snapshot numbering so deltas and acks work as usual.
+// Only the tick loop uses it.
+type spectatorFeed struct {
+	history snapshotHistory
+	pending []delayedFrame // 오래된 것부터
+}
+
+type delayedFrame struct {
+	tick     uint64
+	at       time.Time
+	states   map[string]models.PlayerState
+	messages []models.Message
+}
+
+// queueState queues one state send
+func (f *spectatorFeed) queueState(tick uint64, at time.Time, states map[string]models.PlayerState) {
+	f.pending = append(f.pending, delayedFrame{tick: tick, at: at, states: states})
+}
+
+// queueMessage attaches a message to the latest queued state; before the
+// first state there is nothing to attach it to and it is dropped
+func (f *spectatorFeed) queueMessage(msg models.Message) {
+	if len(f.pending) > 0 {
+		last := &f.pending[len(f.pending)-1]
+		last.messages = append(last.messages, msg)
+	}
+}
+
+// watch sends a room event to spectators: now, or with the delayed state it
+// belongs to
+func (r *Room) watch(msg models.Message) {
+	if r.opts.SpectatorDelay > 0 {
+		r.feed.queueMessage(msg)
+		return
+	}
+	r.sendSpectators(msg)
+}
+
+// sendSpectators sends a message to every spectator in the room. Chat goes
+// through here right away; game events go through watch.
+func (r *Room) sendSpectators(msg models.Message) {
+	frames := encodings{}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for client := range r.clients {
+		if client.spectator {
+			if data := frames.get(client.codec, msg); data != nil {
+				client.enqueue(frame{data: data, typ: msg.Type})
+			}
+		}
+	}
+}
+
+// flushSpectators sends spectators every queued frame that is at least
+// SpectatorDelay old
+func (r *Room) flushSpectators(now time.Time) {
+	if r.opts.SpectatorDelay <= 0 {
+		return
+	}
+	due := 0
+	for due < len(r.feed.pending) && now.Sub(r.feed.pending[due].at) >= r.opts.SpectatorDelay {
+		due++
+	}
+	if due == 0 {
+		return
+	}
+
+	for _, f := range r.feed.pending[:due] {
+		snap := r.feed.history.push(f.tick, f.at, f.states)
+		r.mu.RLock()
+		r.sendSnapshots(&r.feed.history, snap, isSpectator)
+		r.mu.RUnlock()
+		for _, msg := range f.messages {
+			r.sendSpectators(msg)
+		}
+	}
+	// 앞부분을 잘라낸 뒤에도 배열을 재사용
+	n := copy(r.feed.pending, r.feed.pending[due:])
+	clear(r.feed.pending[n:])
+	r.feed.pending = r.feed.pending[:n]
+}
+
+// spectate turns a connection that hasn't logged in into a spectator of its
+// room. follow is the player the client's camera starts on, if any; the
+// camera itself is up to the client.
+func (h *Handler) spectate(client *Client, follow string) {
+	room := client.room
+	room.mu.Lock()
+	if client.loggedIn {
+		room.mu.Unlock()
+		client.reject(models.ErrorCodeAlreadyLoggedIn, errLoggedIn)
+		return
+	}
+	client.spectator = true
+	room.mu.Unlock()
+
+	client.requestFullState()
+	client.Send(models.Message{
+		Type: models.MessageTypeSpectate,
+		Payload: models.SpectatePayload{
+			Room:   room.ID,
+			Follow: follow,
+			Delay:  float64(room.opts.SpectatorDelay) / float64(time.Millisecond),
+			World:  room.game.Config(),
+		},
+	})
+	// 지연 관전이면 라운드 단계도 지연된 상태와 함께 도착
+	if room.opts.SpectatorDelay <= 0 {
+		room.sendRound(client)
+	}
+	if room.playback != nil {
+		client.Send(replayStatusMessage(room.playback.Status()))
+	}
+	h.sendChatHistory(client)
+	h.rooms.warnShutdown(client)
+	log.Printf("Connection %s is spectating room %s", client.player.ID, room.ID)
+}
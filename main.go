@@ -1,22 +1,69 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/websocket/v2"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sangjinsu/websocket-multiplayer/internal/admin"
+	"github.com/sangjinsu/websocket-multiplayer/internal/chat"
+	"github.com/sangjinsu/websocket-multiplayer/internal/config"
 	"github.com/sangjinsu/websocket-multiplayer/internal/game"
 	ws "github.com/sangjinsu/websocket-multiplayer/internal/websocket"
 )
 
 func main() {
+	// 설정 파일(CONFIG_FILE, YAML/JSON) + 환경 변수(PORT 등)
+	cfg, err := config.Load(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	app := fiber.New()
 
-	// Create game instance
-	gameInstance := game.NewGame()
+	// Create room manager (each room runs its own game instance)
+	opts := ws.DefaultOptions()
+	opts.SimRate = cfg.SimRate
+	opts.SendRate = cfg.SendRate
+	opts.SendQueueSize = cfg.SendQueueSize
+	if opts.QueuePolicy, err = ws.ParseQueuePolicy(cfg.QueuePolicy); err != nil {
+		log.Fatal(err)
+	}
+	opts.Interest = ws.Interest{Radius: cfg.Interest.Radius, Width: cfg.Interest.Width, Height: cfg.Interest.Height}
+	opts.Chat = chat.New(cfg.Chat)
+	opts.SpectatorDelay = time.Duration(cfg.SpectatorDelay * float64(time.Second))
+	opts.ReconnectGrace = time.Duration(cfg.ReconnectGrace * float64(time.Second))
+	opts.NoReconnectGrace = cfg.ReconnectGrace == 0
+	opts.ReplayDir = cfg.ReplayDir
+	opts.RecordReplays = cfg.RecordReplays
+	if cfg.ReplayDir != "" {
+		if err := os.MkdirAll(cfg.ReplayDir, 0o755); err != nil {
+			log.Fatal(err)
+		}
+	}
+	rooms := ws.NewRoomManager(func() *game.Game {
+		return game.NewGame(cfg.World)
+	}, opts)
 
 	// Create websocket handler
-	wsHandler := ws.NewHandler(gameInstance)
+	wsHandler := ws.NewHandler(rooms)
+
+	// 관리자 API (ADMIN_TOKEN이 있을 때만)
+	if cfg.AdminToken != "" {
+		admin.New(rooms, cfg.AdminToken).Register(app)
+	} else {
+		log.Printf("ADMIN_TOKEN not set, admin API disabled")
+	}
+
+	// Prometheus 메트릭
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
 
 	// Serve static files
 	app.Static("/", "./public")
@@ -24,16 +71,44 @@ func main() {
 	// WebSocket upgrade handler
 	app.Use("/ws", func(c *fiber.Ctx) error {
 		if websocket.IsWebSocketUpgrade(c) {
+			if rooms.Banned(c.IP()) {
+				return fiber.ErrForbidden
+			}
 			c.Locals("allowed", true)
+			c.Locals("ip", c.IP())
 			return c.Next()
 		}
 		return fiber.ErrUpgradeRequired
 	})
 
 	// WebSocket handler
-	app.Get("/ws", websocket.New(wsHandler.HandleWebSocket))
+	// JSON이 기본, "msgpack" 서브프로토콜(또는 ?encoding=msgpack)로 바이너리 선택
+	app.Get("/ws", websocket.New(wsHandler.HandleWebSocket, websocket.Config{
+		Subprotocols: ws.Subprotocols,
+	}))
 
 	// Start the server
-	log.Println("Server starting on :3000")
-	log.Fatal(app.Listen(":3000"))
+	go func() {
+		log.Printf("Server starting on :%s (arena %gx%g)", cfg.Port, cfg.World.Width, cfg.World.Height)
+		if err := app.Listen(":" + cfg.Port); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	// SIGTERM(docker stop)/Ctrl+C: 새 로그인 중단 -> server_shutdown 카운트다운
+	// -> tick 루프 정지 -> 스냅샷 저장 -> 1001로 연결 종료
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	<-ctx.Done()
+	stop() // 한 번 더 누르면 바로 종료
+
+	countdown := time.Duration(cfg.ShutdownCountdown * float64(time.Second))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), countdown+10*time.Second)
+	defer cancel()
+	if err := rooms.Shutdown(shutdownCtx, countdown, cfg.SnapshotFile); err != nil {
+		log.Printf("Shutdown: %v", err)
+	}
+	if err := app.ShutdownWithContext(shutdownCtx); err != nil {
+		log.Printf("Shutdown: %v", err)
+	}
+	log.Printf("Server stopped")
 }
\ No newline at end of file